@@ -0,0 +1,75 @@
+package goredis
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// aLongTimeAgo is a deadline in the past, which interrupts any I/O in progress.
+var aLongTimeAgo = time.Unix(1, 0)
+
+// bind applies ctx to the I/O of the connection until the returned function is called:
+// the socket deadline is ctx's deadline, and when ctx is cancelled the deadline is set to the past,
+// which interrupts any read or write in progress.
+// The returned function clears the deadline; it must be called before the connection is reused.
+func (c *connection) bind(ctx context.Context) func() {
+	if ctx.Done() == nil {
+		return func() {} // never cancelled, and no deadline
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		c.Conn.SetDeadline(deadline)
+	}
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			c.Conn.SetDeadline(aLongTimeAgo)
+		case <-stop:
+		}
+	}()
+	return func() {
+		close(stop)
+		<-stopped
+		c.Conn.SetDeadline(time.Time{})
+	}
+}
+
+// execute sends a command and receives its reply, within ctx.
+func (c *connection) execute(ctx context.Context, args ...interface{}) (*Reply, error) {
+	rp, _, err := c.exchange(ctx, args...)
+	return rp, err
+}
+
+// exchange is execute, which also reports whether the command was sent in full.
+func (c *connection) exchange(ctx context.Context, args ...interface{}) (*Reply, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	release := c.bind(ctx)
+	defer release()
+	if err := c.SendCommand(args...); err != nil {
+		return nil, false, err
+	}
+	rp, err := c.RecvReply()
+	return rp, true, err
+}
+
+// ctxErr returns ctx's error in place of an I/O error that ctx ending caused.
+// The socket deadline can pass a moment before ctx reports that its deadline has.
+func ctxErr(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			return context.DeadlineExceeded
+		}
+	}
+	return err
+}
@@ -0,0 +1,231 @@
+package goredis
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// silentServer accepts connections and reads commands, but never replies.
+func silentServer(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go io.Copy(io.Discard, c)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestExecuteCommandContextDeadline(t *testing.T) {
+	redis, err := Dial(&DialConfig{Address: silentServer(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.ClosePool()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := redis.ExecuteCommandContext(ctx, "GET", "key"); err != context.DeadlineExceeded {
+		t.Errorf("returned %v, expected context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("returned after %s", elapsed)
+	}
+	if stats := redis.PoolStats(); stats.InUse != 0 || stats.Idle != 0 {
+		t.Errorf("connection with a pending reply was not closed: %+v", stats)
+	}
+}
+
+func TestExecuteCommandContextCancel(t *testing.T) {
+	redis, err := Dial(&DialConfig{Address: silentServer(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.ClosePool()
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if _, err := redis.WithContext(ctx).Get("key"); err != context.Canceled {
+		t.Errorf("returned %v, expected context.Canceled", err)
+	}
+	if _, err := redis.WithContext(ctx).Get("key"); err != context.Canceled {
+		t.Errorf("with a cancelled context, returned %v, expected context.Canceled", err)
+	}
+}
+
+func TestExecuteCommandContextPoolWait(t *testing.T) {
+	redis, err := Dial(&DialConfig{Network: network, Address: address, Database: db, Password: password, Timeout: timeout, MaxIdle: 1, MaxActive: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.ClosePool()
+	p, err := redis.Pipelining() // holds the only connection
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := redis.ExecuteCommandContext(ctx, "PING"); err != context.DeadlineExceeded {
+		t.Errorf("returned %v, expected context.DeadlineExceeded", err)
+	}
+	p.Close()
+	if err := redis.Ping(); err != nil {
+		t.Error(err)
+	}
+	if stats := redis.PoolStats(); stats.Timeouts != 0 || stats.Waits != 1 {
+		t.Errorf("stats are %+v", stats)
+	}
+}
+
+func TestExecuteCommandContextClearsDeadline(t *testing.T) {
+	redis, err := Dial(&DialConfig{Network: network, Address: address, Database: db, Password: password, Timeout: timeout, MaxIdle: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.ClosePool()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	if _, err := redis.ExecuteCommandContext(ctx, "PING"); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	if err := redis.Ping(); err != nil {
+		t.Errorf("pooled connection kept the context's deadline: %v", err)
+	}
+	if stats := redis.PoolStats(); stats.Dials != 1 {
+		t.Errorf("stats are %+v", stats)
+	}
+}
+
+func TestPipeliningContext(t *testing.T) {
+	redis, err := Dial(&DialConfig{Address: silentServer(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.ClosePool()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	p, err := redis.PipeliningContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Command("PING"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Receive(); err != context.DeadlineExceeded {
+		t.Errorf("returned %v, expected context.DeadlineExceeded", err)
+	}
+	p.Close()
+	if stats := redis.PoolStats(); stats.InUse != 0 || stats.Idle != 0 {
+		t.Errorf("connection with a pending reply was not closed: %+v", stats)
+	}
+}
+
+func TestTransactionContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tr, err := r.TransactionContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Command("SET", "key", "value"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Exec(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := tr.Command("SET", "key", "value"); err != context.Canceled {
+		t.Errorf("returned %v, expected context.Canceled", err)
+	}
+	tr.Close()
+}
+
+func TestTransactionContextCancelBeforeExec(t *testing.T) {
+	redis, err := Dial(&DialConfig{Network: network, Address: address, Database: db, Password: password, Timeout: timeout, MaxIdle: 1, MaxActive: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.ClosePool()
+	ctx, cancel := context.WithCancel(context.Background())
+	tr, err := redis.TransactionContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Command("SET", "key", "queued"); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := tr.Exec(); err != context.Canceled {
+		t.Errorf("Exec returned %v, expected context.Canceled", err)
+	}
+	if err := tr.Discard(); err != context.Canceled {
+		t.Errorf("Discard returned %v, expected context.Canceled", err)
+	}
+	tr.Close()
+	if stats := redis.PoolStats(); stats.InUse != 0 || stats.Idle != 0 {
+		t.Errorf("connection in MULTI state was returned to the pool: %+v", stats)
+	}
+
+	// The next command is executed, not queued in the abandoned transaction.
+	if err := redis.Set("key", "value", 0, 0, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := redis.Get("key"); err != nil || string(value) != "value" {
+		t.Errorf("GET returned %q, %v", value, err)
+	}
+}
+
+func TestTransactionCloseWithoutExec(t *testing.T) {
+	redis, err := Dial(&DialConfig{Network: network, Address: address, Database: db, Password: password, Timeout: timeout, MaxIdle: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.ClosePool()
+	tr, err := redis.Transaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.Close()
+	if stats := redis.PoolStats(); stats.Idle != 0 {
+		t.Errorf("connection in MULTI state was returned to the pool: %+v", stats)
+	}
+	if tr, err = redis.Transaction(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Discard(); err != nil {
+		t.Fatal(err)
+	}
+	tr.Close()
+	if stats := redis.PoolStats(); stats.Idle != 1 {
+		t.Errorf("connection was not returned to the pool after DISCARD: %+v", stats)
+	}
+}
+
+func TestPubSubContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	p, err := r.PubSubContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err := p.Subscribe("channel"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Receive(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Receive(); err != context.DeadlineExceeded {
+		t.Errorf("waiting for a message returned %v, expected context.DeadlineExceeded", err)
+	}
+}
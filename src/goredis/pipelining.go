@@ -1,47 +1,79 @@
 package goredis
 
+import (
+	"context"
+)
+
 // Pipelined implements redis pipeline mode.
 // A Request/Response server can be implemented so that it is able to process new requests
 // even if the client didn't already read the old responses.
 // This way it is possible to send multiple commands to the server without waiting for the replies at all,
 // and finally read the replies in a single step.
 type Pipelined struct {
-	redis *Redis
-	conn  *connection
-	times int
+	redis   *Redis
+	pool    *connPool
+	conn    *connection
+	times   int
+	ctx     context.Context
+	release func()
+	broken  bool
 }
 
 // Pipelining new a Pipelined from *redis.
 func (r *Redis) Pipelining() (*Pipelined, error) {
-	c, err := r.pool.Get()
+	return r.PipeliningContext(r.context())
+}
+
+// PipeliningContext new a Pipelined from *redis, bound to ctx until it is closed:
+// if ctx ends, the command or receive in progress fails with ctx's error.
+func (r *Redis) PipeliningContext(ctx context.Context) (*Pipelined, error) {
+	pool := r.masterPool()
+	c, err := pool.GetContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &Pipelined{r, c, 0}, nil
+	return &Pipelined{redis: r, pool: pool, conn: c, ctx: ctx, release: c.bind(ctx)}, nil
 }
 
 // Close closes current pipeline mode.
+// The connection is returned to the pool only if every reply has been received;
+// otherwise it is closed.
 func (p *Pipelined) Close() {
-	p.redis.pool.Put(p.conn)
+	p.release()
+	if p.broken || p.times != 0 {
+		p.pool.Remove(p.conn)
+	} else {
+		p.pool.Put(p.conn)
+	}
 	p.times = 0
 }
 
 // Command send raw redis command and do not wait for response.
 func (p *Pipelined) Command(args ...interface{}) error {
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
 	err := p.conn.SendCommand(args...)
 	if err == nil {
 		p.times++
+	} else {
+		p.broken = true
 	}
-	return err
+	return ctxErr(p.ctx, err)
 }
 
 // Receive wait for one the response.
 func (p *Pipelined) Receive() (*Reply, error) {
+	if err := p.ctx.Err(); err != nil {
+		return nil, err
+	}
 	rp, err := p.conn.RecvReply()
 	if err == nil {
 		p.times--
+	} else {
+		p.broken = true
 	}
-	return rp, err
+	return rp, ctxErr(p.ctx, err)
 }
 
 // ReceiveAll wait for all the responses before.
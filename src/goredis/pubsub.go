@@ -1,6 +1,7 @@
 package goredis
 
 import (
+	"context"
 	"errors"
 	"strconv"
 	"strings"
@@ -18,8 +19,11 @@ func (r *Redis) Publish(channel, message string) (int64, error) {
 
 // PubSub doc: http://redis.io/topics/pubsub
 type PubSub struct {
-	redis *Redis
-	conn  *connection
+	redis   *Redis
+	pool    *connPool
+	conn    *connection
+	ctx     context.Context
+	release func()
 
 	Patterns map[string]bool
 	Channels map[string]bool
@@ -27,21 +31,33 @@ type PubSub struct {
 
 // PubSub new a PubSub from *redis.
 func (r *Redis) PubSub() (*PubSub, error) {
-	c, err := r.pool.Get()
+	return r.PubSubContext(r.context())
+}
+
+// PubSubContext new a PubSub from *redis, bound to ctx until it is closed:
+// if ctx ends, the receive in progress, e.g. a wait for the next message, fails with ctx's error.
+func (r *Redis) PubSubContext(ctx context.Context) (*PubSub, error) {
+	pool := r.masterPool()
+	c, err := pool.GetContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return &PubSub{
 		redis:    r,
+		pool:     pool,
 		conn:     c,
+		ctx:      ctx,
+		release:  c.bind(ctx),
 		Patterns: make(map[string]bool),
 		Channels: make(map[string]bool),
 	}, nil
 }
 
 // Close closes current pubsub command.
+// The connection, which is in subscribed state, is closed rather than returned to the pool.
 func (p *PubSub) Close() error {
-	return p.conn.Conn.Close()
+	p.release()
+	return p.pool.Remove(p.conn)
 }
 
 // Receive returns the reply of pubsub command.
@@ -58,7 +74,7 @@ func (p *PubSub) Close() error {
 func (p *PubSub) Receive() ([]string, error) {
 	rp, err := p.conn.RecvReply()
 	if err != nil {
-		return nil, err
+		return nil, ctxErr(p.ctx, err)
 	}
 	command, err := rp.Multi[0].StringValue()
 	if err != nil {
@@ -126,23 +142,23 @@ func (p *PubSub) Receive() ([]string, error) {
 // Subscribe channel [channel ...]
 func (p *PubSub) Subscribe(channels ...string) error {
 	args := packArgs("SUBSCRIBE", channels)
-	return p.conn.SendCommand(args...)
+	return ctxErr(p.ctx, p.conn.SendCommand(args...))
 }
 
 // PSubscribe pattern [pattern ...]
 func (p *PubSub) PSubscribe(patterns ...string) error {
 	args := packArgs("PSUBSCRIBE", patterns)
-	return p.conn.SendCommand(args...)
+	return ctxErr(p.ctx, p.conn.SendCommand(args...))
 }
 
 // UnSubscribe [channel [channel ...]]
 func (p *PubSub) UnSubscribe(channels ...string) error {
 	args := packArgs("UNSUBSCRIBE", channels)
-	return p.conn.SendCommand(args...)
+	return ctxErr(p.ctx, p.conn.SendCommand(args...))
 }
 
 // PUnSubscribe [pattern [pattern ...]]
 func (p *PubSub) PUnSubscribe(patterns ...string) error {
 	args := packArgs("PUNSUBSCRIBE", patterns)
-	return p.conn.SendCommand(args...)
+	return ctxErr(p.ctx, p.conn.SendCommand(args...))
 }
@@ -1,7 +1,20 @@
 package goredis
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"path/filepath"
+	"reflect"
+	"sync"
 	"testing"
 	"time"
 )
@@ -19,7 +32,7 @@ var (
 )
 
 func init() {
-	client, err := Dial(&DialConfig{network, address, db, password, timeout, maxidle})
+	client, err := Dial(&DialConfig{Network: network, Address: address, Database: db, Password: password, Timeout: timeout, MaxIdle: maxidle})
 	if err != nil {
 		panic(err)
 	}
@@ -27,7 +40,7 @@ func init() {
 }
 
 func TestDial(t *testing.T) {
-	redis, err := Dial(&DialConfig{network, address, db, password, timeout, maxidle})
+	redis, err := Dial(&DialConfig{Network: network, Address: address, Database: db, Password: password, Timeout: timeout, MaxIdle: maxidle})
 	if err != nil {
 		t.Error(err)
 	} else if err := redis.Ping(); err != nil {
@@ -37,7 +50,7 @@ func TestDial(t *testing.T) {
 }
 
 func TestDialTimeout(t *testing.T) {
-	redis, err := Dial(&DialConfig{network, address, db, password, timeout, maxidle})
+	redis, err := Dial(&DialConfig{Network: network, Address: address, Database: db, Password: password, Timeout: timeout, MaxIdle: maxidle})
 	if err != nil {
 		t.Error(err)
 	} else if err := redis.Ping(); err != nil {
@@ -138,7 +151,7 @@ func TestNewDialConfigFromURL(t *testing.T) {
 			t.Errorf("Dataset %d: MaxIdle should match. Expected: %d, got: %d", i, expectedDialConfig.MaxIdle, dialConfig.MaxIdle)
 		}
 		if dialConfig.Network != expectedDialConfig.Network {
-			t.Errorf("Dataset %d: Networks should match. Expected: %d, got: %d", i, expectedDialConfig.Network, dialConfig.Network)
+			t.Errorf("Dataset %d: Networks should match. Expected: %s, got: %s", i, expectedDialConfig.Network, dialConfig.Network)
 		}
 		if dialConfig.Password != expectedDialConfig.Password {
 			t.Errorf("Dataset %d: Passwords should match. Expected: %s, got: %s", i, expectedDialConfig.Password, dialConfig.Password)
@@ -148,3 +161,405 @@ func TestNewDialConfigFromURL(t *testing.T) {
 		}
 	}
 }
+
+func TestNewDialConfigFromURLPoolOptions(t *testing.T) {
+	dialConfig, err := newDialConfigFromURLString("tcp://" + address + "/1?maxidle=2&maxactive=10&pooltimeout=3s&idletimeout=1m&maxconnlifetime=1h&testonborrow=true&maxattempts=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dialConfig.MaxIdle != 2 || dialConfig.MaxActive != 10 || dialConfig.PoolTimeout != 3*time.Second ||
+		dialConfig.IdleTimeout != time.Minute || dialConfig.MaxConnLifetime != time.Hour || !dialConfig.TestOnBorrow || dialConfig.Retry.MaxAttempts != 3 {
+		t.Errorf("pool options not parsed: %+v", dialConfig)
+	}
+	for _, query := range []string{"maxactive=x", "pooltimeout=x", "idletimeout=x", "maxconnlifetime=x", "testonborrow=x", "maxattempts=x"} {
+		if _, err := newDialConfigFromURLString("tcp://" + address + "/1?" + query); err == nil {
+			t.Errorf("%s: Expected error, but none was returned", query)
+		}
+	}
+}
+
+// pipeConn is a connection to nowhere, which records whether it has been closed.
+type pipeConn struct {
+	net.Conn
+	mutex  sync.Mutex
+	closed bool
+}
+
+func (c *pipeConn) Close() error {
+	c.mutex.Lock()
+	c.closed = true
+	c.mutex.Unlock()
+	return c.Conn.Close()
+}
+
+func (c *pipeConn) isClosed() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.closed
+}
+
+func newPipePool() *connPool {
+	return newConnPool(func(ctx context.Context) (*connection, error) {
+		client, server := net.Pipe()
+		server.Close()
+		return &connection{Conn: &pipeConn{Conn: client}}, nil
+	})
+}
+
+func TestPoolMaxActive(t *testing.T) {
+	p := newPipePool()
+	p.MaxIdle = 2
+	p.MaxActive = 2
+	p.Timeout = 50 * time.Millisecond
+	c1, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Get(); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := p.Get(); err != ErrPoolTimeout {
+		t.Fatalf("Get of a third connection returned %v, expected ErrPoolTimeout", err)
+	}
+	if waited := time.Since(start); waited < p.Timeout {
+		t.Errorf("Get timed out after %s, expected %s", waited, p.Timeout)
+	}
+
+	p.Timeout = 0
+	got := make(chan *connection)
+	go func() {
+		c, _ := p.Get()
+		got <- c
+	}()
+	time.Sleep(20 * time.Millisecond)
+	p.Put(c1)
+	select {
+	case c := <-got:
+		if c != c1 {
+			t.Error("waiting Get did not return the connection that was put")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiting Get did not return")
+	}
+
+	stats := p.Stats()
+	if stats.InUse != 2 || stats.Idle != 0 || stats.Dials != 2 || stats.Waits != 2 || stats.Timeouts != 1 {
+		t.Errorf("stats are %+v", stats)
+	}
+}
+
+func TestPoolRemove(t *testing.T) {
+	p := newPipePool()
+	p.MaxIdle = 1
+	p.MaxActive = 1
+	p.Timeout = time.Second
+	c, _ := p.Get()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		p.Remove(c)
+	}()
+	c2, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Conn.(*pipeConn).isClosed() || c2 == c {
+		t.Error("removed connection was not closed, or was reused")
+	}
+	if stats := p.Stats(); stats.InUse != 1 || stats.Dials != 2 {
+		t.Errorf("stats are %+v", stats)
+	}
+}
+
+func TestPoolClose(t *testing.T) {
+	p := newPipePool()
+	p.MaxIdle = 1
+	p.MaxActive = 1
+	c, _ := p.Get()
+	errs := make(chan error)
+	go func() {
+		_, err := p.Get()
+		errs <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	p.Close()
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("Get from a closed pool returned no error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiting Get was not woken by Close")
+	}
+	p.Put(c)
+	if !c.Conn.(*pipeConn).isClosed() {
+		t.Error("connection put into a closed pool was not closed")
+	}
+}
+
+func TestPoolMaxIdle(t *testing.T) {
+	p := newPipePool()
+	p.MaxIdle = 1
+	c1, _ := p.Get()
+	c2, _ := p.Get()
+	p.Put(c1)
+	p.Put(c2)
+	if !c1.Conn.(*pipeConn).isClosed() || c2.Conn.(*pipeConn).isClosed() {
+		t.Error("the oldest idle connection was not the one closed")
+	}
+	if stats := p.Stats(); stats.InUse != 0 || stats.Idle != 1 {
+		t.Errorf("stats are %+v", stats)
+	}
+}
+
+func TestPoolIdleTimeout(t *testing.T) {
+	p := newPipePool()
+	p.MaxIdle = 2
+	p.IdleTimeout = 20 * time.Millisecond
+	c1, _ := p.Get()
+	p.Put(c1)
+	time.Sleep(30 * time.Millisecond)
+	c2, _ := p.Get()
+	if c2 == c1 || !c1.Conn.(*pipeConn).isClosed() {
+		t.Error("connection idle for longer than IdleTimeout was reused or not closed")
+	}
+	p.Put(c2)
+	if c3, _ := p.Get(); c3 != c2 {
+		t.Error("recently idle connection was not reused")
+	}
+}
+
+func TestPoolMaxConnLifetime(t *testing.T) {
+	p := newPipePool()
+	p.MaxIdle = 2
+	p.MaxConnLifetime = 20 * time.Millisecond
+	c1, _ := p.Get()
+	c2, _ := p.Get()
+	p.Put(c2)
+	time.Sleep(30 * time.Millisecond)
+	p.Put(c1)
+	if !c1.Conn.(*pipeConn).isClosed() {
+		t.Error("connection older than MaxConnLifetime was put into the pool")
+	}
+	if c, _ := p.Get(); c == c2 || !c2.Conn.(*pipeConn).isClosed() {
+		t.Error("idle connection older than MaxConnLifetime was reused or not closed")
+	}
+}
+
+func TestPoolTestOnBorrow(t *testing.T) {
+	redis, err := Dial(&DialConfig{Network: network, Address: address, Database: db, Password: password, Timeout: timeout, MaxIdle: 1, TestOnBorrow: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.ClosePool()
+	redis.pool.mutex.Lock()
+	redis.pool.idle.Back().Value.(idleConn).c.Conn.Close() // as if the server had closed it
+	redis.pool.mutex.Unlock()
+	if err := redis.Ping(); err != nil {
+		t.Error(err)
+	}
+	if stats := redis.PoolStats(); stats.Dials != 2 || stats.Idle != 1 {
+		t.Errorf("stats are %+v", stats)
+	}
+}
+
+func TestPoolConcurrency(t *testing.T) {
+	redis, err := Dial(&DialConfig{Network: network, Address: address, Database: db, Password: password, Timeout: timeout, MaxIdle: 3, MaxActive: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.ClosePool()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if err := redis.Ping(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if stats := redis.PoolStats(); stats.Dials > 3 || stats.InUse != 0 || stats.Idle > 3 {
+		t.Errorf("stats are %+v", stats)
+	}
+}
+
+func TestNewDialConfigFromURLSchemes(t *testing.T) {
+	datasets := []struct {
+		urlString string
+		network   string
+		address   string
+		database  int
+		username  string
+		password  string
+		tls       bool
+	}{
+		{"tcp://auth:pw@127.0.0.1:6379/1", "tcp", "127.0.0.1:6379", 1, "", "pw", false},
+		{"redis://user:pw@127.0.0.1:6379/2", "tcp", "127.0.0.1:6379", 2, "user", "pw", false},
+		{"redis://:pw@127.0.0.1:6379", "tcp", "127.0.0.1:6379", 0, "", "pw", false},
+		{"rediss://user:pw@redis.example.com:6380/3", "tcp", "redis.example.com:6380", 3, "user", "pw", true},
+		{"unix:///var/run/redis.sock?db=4", "unix", "/var/run/redis.sock", 4, "", "", false},
+		{"unix://:pw@/var/run/redis.sock", "unix", "/var/run/redis.sock", 0, "", "pw", false},
+	}
+	for _, dataset := range datasets {
+		dialConfig, err := newDialConfigFromURLString(dataset.urlString)
+		if err != nil {
+			t.Errorf("%s: %s", dataset.urlString, err)
+			continue
+		}
+		if dialConfig.Network != dataset.network || dialConfig.Address != dataset.address ||
+			dialConfig.Database != dataset.database || dialConfig.Username != dataset.username ||
+			dialConfig.Password != dataset.password || (dialConfig.TLSConfig != nil) != dataset.tls {
+			t.Errorf("%s: parsed as %+v", dataset.urlString, dialConfig)
+		}
+	}
+	for _, urlString := range []string{"unix://", "unix:///var/run/redis.sock?db=x"} {
+		if _, err := newDialConfigFromURLString(urlString); err == nil {
+			t.Errorf("%s: Expected error, but none was returned", urlString)
+		}
+	}
+}
+
+// standIn serves connections accepted by l by relaying them to the Redis server at address,
+// e.g. to terminate TLS in front of it.
+func standIn(t *testing.T, l net.Listener) {
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				server, err := net.Dial("tcp", address)
+				if err != nil {
+					return
+				}
+				defer server.Close()
+				go io.Copy(server, c)
+				io.Copy(c, server)
+			}()
+		}
+	}()
+}
+
+// selfSignedCertificate returns a certificate for 127.0.0.1 and localhost, and a pool that trusts it.
+func selfSignedCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "goredis test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:              []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+func TestDialTLS(t *testing.T) {
+	cert, roots := selfSignedCertificate(t)
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	standIn(t, l)
+
+	redis, err := Dial(&DialConfig{Address: l.Addr().String(), Database: db, TLSConfig: &tls.Config{RootCAs: roots}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.ClosePool()
+	if err := redis.Set("tls", "value", 0, 0, false, false); err != nil {
+		t.Error(err)
+	}
+	if value, err := redis.Get("tls"); err != nil || string(value) != "value" {
+		t.Errorf("GET returned %q, %v", value, err)
+	}
+
+	if _, err := Dial(&DialConfig{Address: l.Addr().String(), TLSConfig: &tls.Config{}}); err == nil {
+		t.Error("Dial verified a self-signed certificate")
+	}
+	if _, err := Dial(&DialConfig{Address: l.Addr().String(), TLSConfig: &tls.Config{RootCAs: roots, ServerName: "redis.example.com"}}); err == nil {
+		t.Error("Dial accepted a certificate for another server")
+	}
+}
+
+func TestDialUnix(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "redis.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	standIn(t, l)
+
+	redis, err := DialURL("unix://" + socket + "?db=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.ClosePool()
+	if err := redis.Ping(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDialAuth(t *testing.T) {
+	reply := func(n int, command []string) (string, bool) {
+		if command[0] == "PING" {
+			return "+PONG\r\n", false
+		}
+		return "+OK\r\n", false
+	}
+	datasets := []struct {
+		username string
+		password string
+		expected []string
+	}{
+		{"", "", []string{"SELECT 2", "PING"}},
+		{"", "pw", []string{"AUTH pw", "SELECT 2", "PING"}},
+		{"user", "pw", []string{"AUTH user pw", "SELECT 2", "PING"}},
+	}
+	for _, dataset := range datasets {
+		s := newDroppingServer(t, reply)
+		redis, err := Dial(&DialConfig{Address: s.listener.Addr().String(), Database: 2, Username: dataset.username, Password: dataset.password})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := redis.Ping(); err != nil {
+			t.Error(err)
+		}
+		redis.ClosePool()
+		if received := s.received(); !reflect.DeepEqual(received, dataset.expected) {
+			t.Errorf("server received %q, expected %q", received, dataset.expected)
+		}
+	}
+
+	s := newDroppingServer(t, func(n int, command []string) (string, bool) {
+		return "-WRONGPASS invalid username-password pair or user is disabled.\r\n", false
+	})
+	if _, err := Dial(&DialConfig{Address: s.listener.Addr().String(), Username: "user", Password: "wrong"}); err == nil {
+		t.Error("Dial succeeded although AUTH failed")
+	}
+}
@@ -0,0 +1,124 @@
+package goredis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy decides whether a command whose connection breaks is executed again, on a new connection.
+//
+// A command is retried only if the connection failed, e.g. the server closed it:
+// not if the command timed out, or its context ended, or the server replied with an error.
+// If the command could not be sent in full, the server cannot have executed it, and it is retried.
+// Otherwise the server may have executed it before the connection broke,
+// so it is retried only if executing it twice is safe: see IsIdempotent.
+type RetryPolicy struct {
+	// MaxAttempts is the most times that a command is executed; 1 disables retries.
+	MaxAttempts int
+
+	// MinBackoff is the wait before the first retry, which is doubled for each retry after,
+	// up to MaxBackoff (no limit if 0).
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Idempotent reports whether a command, as executed by ExecuteCommand, is safe to execute twice.
+	// It is IsIdempotent if nil.
+	Idempotent func(args ...interface{}) bool
+}
+
+// DefaultMaxAttempts is the default of RetryPolicy.MaxAttempts: one retry.
+const DefaultMaxAttempts = 2
+
+// idempotentCommands are the commands that have the same effect, and reply, if they are executed twice in succession.
+var idempotentCommands = map[string]bool{
+	"AUTH": true, "BITCOUNT": true, "BITPOS": true, "DBSIZE": true, "DUMP": true, "ECHO": true,
+	"EXISTS": true, "EXPIRE": true, "EXPIREAT": true, "GET": true, "GETBIT": true, "GETRANGE": true,
+	"HEXISTS": true, "HGET": true, "HGETALL": true, "HKEYS": true, "HLEN": true, "HMGET": true,
+	"HMSET": true, "HSCAN": true, "HSTRLEN": true, "HVALS": true, "INFO": true, "KEYS": true,
+	"LINDEX": true, "LLEN": true, "LRANGE": true, "MGET": true, "MSET": true, "PEXPIRE": true,
+	"PEXPIREAT": true, "PFCOUNT": true, "PING": true, "PSETEX": true, "PTTL": true, "SCAN": true,
+	"SCARD": true, "SDIFF": true, "SELECT": true, "SET": true, "SETEX": true, "SINTER": true,
+	"SISMEMBER": true, "SMEMBERS": true, "SSCAN": true, "STRLEN": true, "SUNION": true, "TIME": true,
+	"TTL": true, "TYPE": true, "ZCARD": true, "ZCOUNT": true, "ZLEXCOUNT": true, "ZRANGE": true,
+	"ZRANGEBYLEX": true, "ZRANGEBYSCORE": true, "ZRANK": true, "ZREVRANGE": true,
+	"ZREVRANGEBYLEX": true, "ZREVRANGEBYSCORE": true, "ZREVRANK": true, "ZSCAN": true, "ZSCORE": true,
+}
+
+// IsIdempotent reports whether a command is safe to execute twice:
+// whether it only reads, like GET, or it sets a value regardless of the previous one, like MSET.
+// Commands whose effect or reply depends on whether they were executed before,
+// like INCR, LPUSH, DEL, SET with NX or XX, or EVAL, are not.
+func IsIdempotent(args ...interface{}) bool {
+	name := strings.ToUpper(argString(args, 0))
+	if !idempotentCommands[name] {
+		return false
+	}
+	if name == "SET" {
+		for i := 3; i < len(args); i++ {
+			switch strings.ToUpper(argString(args, i)) {
+			case "NX", "XX", "GET":
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func argString(args []interface{}, i int) string {
+	if i >= len(args) {
+		return ""
+	}
+	switch v := args[i].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
+
+// isConnectionError reports whether err means that the connection is broken.
+func isConnectionError(err error) bool {
+	return err == io.EOF || err == io.ErrUnexpectedEOF ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNABORTED)
+}
+
+// shouldRetry reports whether a command that failed with err on the attempt'th attempt should be retried.
+func (policy *RetryPolicy) shouldRetry(attempt int, err error, sent bool, args []interface{}) bool {
+	if attempt >= policy.MaxAttempts || !isConnectionError(err) {
+		return false
+	}
+	if !sent {
+		return true
+	}
+	if policy.Idempotent != nil {
+		return policy.Idempotent(args...)
+	}
+	return IsIdempotent(args...)
+}
+
+// backoff waits before the retry that follows the attempt'th attempt, unless ctx ends first.
+func (policy *RetryPolicy) backoff(ctx context.Context, attempt int) error {
+	wait := policy.MinBackoff
+	for i := 1; i < attempt && (policy.MaxBackoff == 0 || wait < policy.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if policy.MaxBackoff > 0 && wait > policy.MaxBackoff {
+		wait = policy.MaxBackoff
+	}
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
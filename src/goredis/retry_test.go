@@ -0,0 +1,247 @@
+package goredis
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// droppingServer is a fake server, which replies to each command as its handler says,
+// and drops connections when told to, e.g. in the middle of a reply.
+type droppingServer struct {
+	listener net.Listener
+	// handle returns the raw reply to the n'th command received (counting from 1),
+	// and whether to close the connection after writing it.
+	handle func(n int, command []string) (reply string, drop bool)
+
+	mutex    sync.Mutex
+	commands []string
+}
+
+func newDroppingServer(t *testing.T, handle func(n int, command []string) (string, bool)) *droppingServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	s := &droppingServer{listener: l, handle: handle}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+func (s *droppingServer) serve(c net.Conn) {
+	defer c.Close()
+	reader := bufio.NewReader(c)
+	for {
+		command, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		s.mutex.Lock()
+		s.commands = append(s.commands, strings.Join(command, " "))
+		n := len(s.commands)
+		s.mutex.Unlock()
+		reply, drop := s.handle(n, command)
+		io.WriteString(c, reply)
+		if drop {
+			return
+		}
+	}
+}
+
+func (s *droppingServer) received() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	command := make([]string, n)
+	for i := range command {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		command[i] = string(buf[:size])
+	}
+	return command, nil
+}
+
+// dropFirst drops the connection in the middle of the reply to the first n commands.
+func dropFirst(n int) func(int, []string) (string, bool) {
+	return func(i int, command []string) (string, bool) {
+		if i <= n {
+			return "$5\r\nhel", true
+		}
+		if strings.ToUpper(command[0]) == "INCR" {
+			return ":1\r\n", false
+		}
+		return "$5\r\nhello\r\n", false
+	}
+}
+
+func dialDroppingServer(t *testing.T, s *droppingServer, retry RetryPolicy) *Redis {
+	redis, err := Dial(&DialConfig{Address: s.listener.Addr().String(), Retry: retry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(redis.ClosePool)
+	return redis
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	s := newDroppingServer(t, dropFirst(1))
+	redis := dialDroppingServer(t, s, RetryPolicy{})
+	value, err := redis.Get("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "hello" {
+		t.Errorf("GET returned %q", value)
+	}
+	if received := s.received(); len(received) != 2 {
+		t.Errorf("server received %q, expected GET twice", received)
+	}
+	if stats := redis.PoolStats(); stats.InUse != 0 || stats.Idle != 1 || stats.Dials != 2 {
+		t.Errorf("broken connection was not discarded: %+v", stats)
+	}
+}
+
+func TestRetryNotIdempotent(t *testing.T) {
+	s := newDroppingServer(t, dropFirst(2))
+	redis := dialDroppingServer(t, s, RetryPolicy{MaxAttempts: 5})
+	if _, err := redis.Incr("key"); err != io.ErrUnexpectedEOF {
+		t.Errorf("INCR returned %v, expected io.ErrUnexpectedEOF", err)
+	}
+	if err := redis.Set("key", "value", 0, 0, false, true); err != io.ErrUnexpectedEOF {
+		t.Errorf("SET NX returned %v, expected io.ErrUnexpectedEOF", err)
+	}
+	if received := s.received(); len(received) != 2 {
+		t.Errorf("server received %q, expected INCR and SET once each", received)
+	}
+	if stats := redis.PoolStats(); stats.InUse != 0 || stats.Idle != 0 {
+		t.Errorf("broken connection was not discarded: %+v", stats)
+	}
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	s := newDroppingServer(t, dropFirst(2))
+	redis := dialDroppingServer(t, s, RetryPolicy{MaxAttempts: 2})
+	if _, err := redis.Get("key"); err != io.ErrUnexpectedEOF {
+		t.Errorf("GET returned %v, expected io.ErrUnexpectedEOF", err)
+	}
+	if received := s.received(); len(received) != 2 {
+		t.Errorf("server received %q, expected GET twice", received)
+	}
+
+	s = newDroppingServer(t, dropFirst(1))
+	redis = dialDroppingServer(t, s, RetryPolicy{MaxAttempts: 1})
+	if _, err := redis.Get("key"); err != io.ErrUnexpectedEOF {
+		t.Errorf("with retries disabled, GET returned %v, expected io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	s := newDroppingServer(t, dropFirst(3))
+	redis := dialDroppingServer(t, s, RetryPolicy{MaxAttempts: 4, MinBackoff: 20 * time.Millisecond, MaxBackoff: 30 * time.Millisecond})
+	start := time.Now()
+	if _, err := redis.Get("key"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond { // 20 + 30 + 30
+		t.Errorf("retries took %s, expected backoff of 80ms", elapsed)
+	}
+
+	s = newDroppingServer(t, dropFirst(1))
+	redis = dialDroppingServer(t, s, RetryPolicy{MinBackoff: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := redis.ExecuteCommandContext(ctx, "GET", "key"); err != context.DeadlineExceeded {
+		t.Errorf("GET returned %v, expected context.DeadlineExceeded during the backoff", err)
+	}
+}
+
+func TestRetryCustomIdempotent(t *testing.T) {
+	s := newDroppingServer(t, dropFirst(1))
+	redis := dialDroppingServer(t, s, RetryPolicy{Idempotent: func(args ...interface{}) bool {
+		return strings.ToUpper(argString(args, 0)) == "INCR"
+	}})
+	if n, err := redis.Incr("key"); err != nil || n != 1 {
+		t.Errorf("INCR returned %d, %v", n, err)
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 2}
+	incr := []interface{}{"INCR", "key"}
+	if !policy.shouldRetry(1, io.EOF, false, incr) {
+		t.Error("a command that was not sent was not retried")
+	}
+	if policy.shouldRetry(1, io.EOF, true, incr) {
+		t.Error("INCR, which was sent, was retried")
+	}
+	if policy.shouldRetry(1, context.Canceled, false, incr) {
+		t.Error("a cancelled command was retried")
+	}
+	if policy.shouldRetry(2, io.EOF, false, incr) {
+		t.Error("a command was retried after MaxAttempts")
+	}
+}
+
+func TestIsIdempotent(t *testing.T) {
+	for _, args := range [][]interface{}{
+		{"GET", "key"},
+		{"get", "key"},
+		{[]byte("MGET"), "a", "b"},
+		{"SET", "key", "value"},
+		{"SET", "key", "value", "EX", 10},
+		{"HGETALL", "key"},
+		{"PING"},
+	} {
+		if !IsIdempotent(args...) {
+			t.Errorf("%v is idempotent", args)
+		}
+	}
+	for _, args := range [][]interface{}{
+		{"INCR", "key"},
+		{"LPUSH", "key", "value"},
+		{"DEL", "key"},
+		{"SET", "key", "value", "NX"},
+		{"SET", "key", "value", "PX", 10, "xx"},
+		{"EVAL", "return 1", 0},
+		{},
+	} {
+		if IsIdempotent(args...) {
+			t.Errorf("%v is not idempotent", args)
+		}
+	}
+}
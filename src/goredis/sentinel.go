@@ -0,0 +1,327 @@
+package goredis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sentinelRetryInterval is the wait between attempts to reach a sentinel, once none can be reached.
+const sentinelRetryInterval = time.Second
+
+// readOnlyCommands are the commands that a client of DialSentinel with ReadFromReplicas executes on a replica.
+var readOnlyCommands = map[string]bool{
+	"BITCOUNT": true, "BITPOS": true, "DBSIZE": true, "DUMP": true, "EXISTS": true, "GET": true,
+	"GETBIT": true, "GETRANGE": true, "HEXISTS": true, "HGET": true, "HGETALL": true, "HKEYS": true,
+	"HLEN": true, "HMGET": true, "HSCAN": true, "HSTRLEN": true, "HVALS": true, "KEYS": true,
+	"LINDEX": true, "LLEN": true, "LRANGE": true, "MGET": true, "PTTL": true, "RANDOMKEY": true,
+	"SCAN": true, "SCARD": true, "SDIFF": true, "SINTER": true, "SISMEMBER": true, "SMEMBERS": true,
+	"SRANDMEMBER": true, "SSCAN": true, "STRLEN": true, "SUNION": true, "TTL": true, "TYPE": true,
+	"ZCARD": true, "ZCOUNT": true, "ZLEXCOUNT": true, "ZRANGE": true, "ZRANGEBYLEX": true,
+	"ZRANGEBYSCORE": true, "ZRANK": true, "ZREVRANGE": true, "ZREVRANGEBYLEX": true,
+	"ZREVRANGEBYSCORE": true, "ZREVRANK": true, "ZSCAN": true, "ZSCORE": true,
+}
+
+// sentinel follows the master that the sentinels monitor under masterName,
+// and keeps a pool of connections to it, and to each of its replicas if readFromReplicas.
+// When the sentinels fail the master over, its pool is closed, and replaced by one for the new master;
+// connections taken from the old pool are closed when they are returned to it.
+type sentinel struct {
+	client           *Redis
+	cfg              DialConfig
+	masterName       string
+	addrs            []string // of the sentinels, the one last reached first
+	readFromReplicas bool
+	cancel           context.CancelFunc
+	done             chan struct{} // closed when watch returns
+
+	mutex      sync.Mutex
+	master     *connPool
+	masterAddr string
+	replicas   []replica
+	next       int // the replica that executed the last read-only command
+	closed     bool
+}
+
+type replica struct {
+	address string
+	pool    *connPool
+}
+
+// DialSentinel new a redis client of the master that the sentinels at the given addresses monitor under masterName.
+//
+// The client asks the first sentinel that it can reach for the master's address,
+// and subscribes to its +switch-master events: when the sentinels fail the master over,
+// the client closes its connections to the old master, and executes later commands on the new one.
+// If the sentinel becomes unreachable, the client asks the others, in turn, and subscribes to the first that replies.
+//
+// cfg configures the connections to the master, as for Dial, except that its Address is ignored.
+// Connections to the sentinels use its Timeout and TLSConfig, and authenticate with SentinelPassword, if it is set.
+// If ReadFromReplicas, read-only commands like GET are executed on the master's replicas in turn, if it has any
+// that the sentinels report to be up; those replicas may lag behind the master.
+// Pipelines, transactions, PubSubs and MONITOR always use the master.
+func DialSentinel(masterName string, sentinels []string, cfg *DialConfig) (*Redis, error) {
+	return DialSentinelContext(context.Background(), masterName, sentinels, cfg)
+}
+
+// DialSentinelContext is DialSentinel with a context, which bounds the discovery of the master,
+// and the first connection to it.
+func DialSentinelContext(ctx context.Context, masterName string, sentinels []string, cfg *DialConfig) (*Redis, error) {
+	if cfg == nil {
+		cfg = &DialConfig{}
+	}
+	if len(sentinels) == 0 {
+		return nil, errors.New("no sentinel addresses")
+	}
+	r := newRedis(cfg)
+	watchCtx, cancel := context.WithCancel(context.Background())
+	s := &sentinel{
+		client:           r,
+		cfg:              *cfg,
+		masterName:       masterName,
+		addrs:            append([]string(nil), sentinels...),
+		readFromReplicas: cfg.ReadFromReplicas,
+		cancel:           cancel,
+	}
+	r.sentinel = s
+	sc, err := s.connect(ctx)
+	if err != nil {
+		s.close()
+		return nil, err
+	}
+	if err := s.discover(ctx, sc); err != nil {
+		sc.ClosePool()
+		s.close()
+		return nil, err
+	}
+	conn, err := s.masterPool().GetContext(ctx)
+	if err != nil {
+		sc.ClosePool()
+		s.close()
+		return nil, err
+	}
+	s.masterPool().Put(conn)
+	s.done = make(chan struct{})
+	go s.watch(watchCtx, sc)
+	return r, nil
+}
+
+// connect returns a client of the first sentinel that can be reached, which is moved to the front of the list.
+func (s *sentinel) connect(ctx context.Context) (*Redis, error) {
+	var lastErr error
+	for i, addr := range s.addrs {
+		sc, err := DialContext(ctx, &DialConfig{
+			Address:   addr,
+			Password:  s.cfg.SentinelPassword,
+			TLSConfig: s.cfg.TLSConfig,
+			Timeout:   s.cfg.Timeout,
+		})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		copy(s.addrs[1:i+1], s.addrs[:i])
+		s.addrs[0] = addr
+		return sc, nil
+	}
+	return nil, lastErr
+}
+
+// discover asks the sentinel for the addresses of the master, and of its replicas if readFromReplicas.
+func (s *sentinel) discover(ctx context.Context, sc *Redis) error {
+	rp, err := sc.ExecuteCommandContext(ctx, "SENTINEL", "get-master-addr-by-name", s.masterName)
+	if err != nil {
+		return err
+	}
+	addr, err := rp.ListValue()
+	if err != nil {
+		return err
+	}
+	if len(addr) != 2 {
+		return errors.New("sentinel does not know master " + s.masterName)
+	}
+	s.switchMaster(net.JoinHostPort(addr[0], addr[1]))
+	return s.discoverReplicas(ctx, sc)
+}
+
+// discoverReplicas asks the sentinel for the addresses of the master's replicas that are up, if readFromReplicas.
+func (s *sentinel) discoverReplicas(ctx context.Context, sc *Redis) error {
+	if !s.readFromReplicas {
+		return nil
+	}
+	rp, err := sc.ExecuteCommandContext(ctx, "SENTINEL", "slaves", s.masterName)
+	if err != nil {
+		return err
+	}
+	replies, err := rp.MultiValue()
+	if err != nil {
+		return err
+	}
+	var addrs []string
+	for _, reply := range replies {
+		info, err := reply.HashValue()
+		if err != nil {
+			return err
+		}
+		flags := info["flags"]
+		if info["master-link-status"] != "ok" || strings.Contains(flags, "s_down") ||
+			strings.Contains(flags, "o_down") || strings.Contains(flags, "disconnected") {
+			continue
+		}
+		addrs = append(addrs, net.JoinHostPort(info["ip"], info["port"]))
+	}
+	s.setReplicas(addrs)
+	return nil
+}
+
+// watch follows the master's failovers, as the sentinel sc and then the others report them, until ctx ends.
+func (s *sentinel) watch(ctx context.Context, sc *Redis) {
+	defer close(s.done)
+	for {
+		s.follow(ctx, sc)
+		sc.ClosePool()
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+			var err error
+			if sc, err = s.connect(ctx); err == nil {
+				break
+			}
+			timer := time.NewTimer(sentinelRetryInterval)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}
+}
+
+// follow subscribes to the sentinel's events, and applies those about the master, until the connection fails.
+func (s *sentinel) follow(ctx context.Context, sc *Redis) {
+	ps, err := sc.PubSubContext(ctx)
+	if err != nil {
+		return
+	}
+	defer ps.Close()
+	channels := []string{"+switch-master"}
+	if s.readFromReplicas {
+		channels = append(channels, "+slave", "+sdown", "-sdown")
+	}
+	if err := ps.Subscribe(channels...); err != nil {
+		return
+	}
+	for range channels {
+		if _, err := ps.Receive(); err != nil {
+			return
+		}
+	}
+	// The master may have failed over since it was last discovered, e.g. while no sentinel could be reached.
+	if err := s.discover(ctx, sc); err != nil {
+		return
+	}
+	for {
+		message, err := ps.Receive()
+		if err != nil {
+			return
+		}
+		if message[0] != "message" {
+			continue
+		}
+		fields := strings.Fields(message[2])
+		if message[1] == "+switch-master" {
+			// <master name> <old ip> <old port> <new ip> <new port>
+			if len(fields) != 5 || fields[0] != s.masterName {
+				continue
+			}
+			s.switchMaster(net.JoinHostPort(fields[3], fields[4]))
+		} else if len(fields) < 6 || fields[0] != "slave" || fields[4] != "@" || fields[5] != s.masterName {
+			// slave <ip>:<port> <ip> <port> @ <master name> <master ip> <master port>
+			continue
+		}
+		if err := s.discoverReplicas(ctx, sc); err != nil {
+			return
+		}
+	}
+}
+
+// switchMaster replaces the pool of connections to the master, unless it is that of addr already.
+func (s *sentinel) switchMaster(addr string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed || addr == s.masterAddr {
+		return
+	}
+	if s.master != nil {
+		s.master.Close()
+	}
+	s.master = s.client.newPool(addr, &s.cfg)
+	s.masterAddr = addr
+}
+
+// setReplicas keeps the pools of connections to the replicas at addrs, and closes the others.
+func (s *sentinel) setReplicas(addrs []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	pools := make(map[string]*connPool)
+	for _, r := range s.replicas {
+		pools[r.address] = r.pool
+	}
+	replicas := make([]replica, 0, len(addrs))
+	for _, addr := range addrs {
+		pool, found := pools[addr]
+		if found {
+			delete(pools, addr)
+		} else {
+			pool = s.client.newPool(addr, &s.cfg)
+		}
+		replicas = append(replicas, replica{addr, pool})
+	}
+	for _, pool := range pools {
+		pool.Close()
+	}
+	s.replicas = replicas
+}
+
+func (s *sentinel) masterPool() *connPool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.master
+}
+
+// commandPool returns the pool of the next replica, in turn, if the command is read-only and there are replicas;
+// otherwise the master's.
+func (s *sentinel) commandPool(args []interface{}) *connPool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.replicas) > 0 && readOnlyCommands[strings.ToUpper(argString(args, 0))] {
+		s.next = (s.next + 1) % len(s.replicas)
+		return s.replicas[s.next].pool
+	}
+	return s.master
+}
+
+// close stops following the master, and closes the pools.
+func (s *sentinel) close() {
+	s.cancel()
+	if s.done != nil {
+		<-s.done
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+	if s.master != nil {
+		s.master.Close()
+	}
+	for _, r := range s.replicas {
+		r.pool.Close()
+	}
+}
@@ -0,0 +1,298 @@
+package goredis
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSentinel is a fake sentinel, which reports the master and replicas that it is told to,
+// and publishes +switch-master when it is told that the master failed over.
+type fakeSentinel struct {
+	listener net.Listener
+
+	mutex       sync.Mutex
+	master      string
+	replicas    []string // of the form host:port flags master-link-status
+	conns       []net.Conn
+	subscribers []net.Conn
+}
+
+func newFakeSentinel(t *testing.T, master string, replicas ...string) *fakeSentinel {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSentinel{listener: l, master: master, replicas: replicas}
+	t.Cleanup(s.stop)
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			s.mutex.Lock()
+			s.conns = append(s.conns, c)
+			s.mutex.Unlock()
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSentinel) serve(c net.Conn) {
+	reader := bufio.NewReader(c)
+	for {
+		command, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		s.mutex.Lock()
+		switch {
+		case len(command) == 3 && command[0] == "SENTINEL" && command[1] == "get-master-addr-by-name":
+			if command[2] != "mymaster" {
+				io.WriteString(c, "*-1\r\n")
+				break
+			}
+			host, port, _ := net.SplitHostPort(s.master)
+			io.WriteString(c, multiBulk(host, port))
+		case len(command) == 3 && command[0] == "SENTINEL" && command[1] == "slaves":
+			reply := fmt.Sprintf("*%d\r\n", len(s.replicas))
+			for _, r := range s.replicas {
+				fields := strings.Fields(r)
+				host, port, _ := net.SplitHostPort(fields[0])
+				reply += multiBulk("name", fields[0], "ip", host, "port", port, "flags", fields[1],
+					"master-link-status", fields[2])
+			}
+			io.WriteString(c, reply)
+		case command[0] == "SUBSCRIBE":
+			for i, channel := range command[1:] {
+				io.WriteString(c, fmt.Sprintf("*3\r\n$9\r\nsubscribe\r\n$%d\r\n%s\r\n:%d\r\n", len(channel), channel, i+1))
+			}
+			s.subscribers = append(s.subscribers, c)
+		default:
+			io.WriteString(c, "-ERR unknown command\r\n")
+		}
+		s.mutex.Unlock()
+	}
+}
+
+// failover reports that the master is now at addr, and publishes +switch-master.
+func (s *fakeSentinel) failover(addr string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	oldHost, oldPort, _ := net.SplitHostPort(s.master)
+	newHost, newPort, _ := net.SplitHostPort(addr)
+	s.master = addr
+	s.publish("+switch-master", strings.Join([]string{"mymaster", oldHost, oldPort, newHost, newPort}, " "))
+}
+
+// setReplicas reports replicas, and publishes +slave, as a sentinel does when it discovers one.
+func (s *fakeSentinel) setReplicas(replicas ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.replicas = replicas
+	host, port, _ := net.SplitHostPort(s.master)
+	s.publish("+slave", "slave 127.0.0.1:1 127.0.0.1 1 @ mymaster "+host+" "+port)
+}
+
+// publish sends a message to the subscribers. The mutex must be held.
+func (s *fakeSentinel) publish(channel, message string) {
+	for _, c := range s.subscribers {
+		io.WriteString(c, multiBulk("message", channel, message))
+	}
+}
+
+func (s *fakeSentinel) subscribed() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.subscribers) > 0
+}
+
+// stop closes the listener and every connection, as if the sentinel had gone down.
+func (s *fakeSentinel) stop() {
+	s.listener.Close()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, c := range s.conns {
+		c.Close()
+	}
+	s.subscribers = nil
+}
+
+func multiBulk(items ...string) string {
+	reply := fmt.Sprintf("*%d\r\n", len(items))
+	for _, item := range items {
+		reply += fmt.Sprintf("$%d\r\n%s\r\n", len(item), item)
+	}
+	return reply
+}
+
+// namedServer is a fake server, which replies to GET with its name, and to any other command with OK.
+func namedServer(t *testing.T, name string) *droppingServer {
+	return newDroppingServer(t, func(n int, command []string) (string, bool) {
+		if strings.ToUpper(command[0]) == "GET" {
+			return fmt.Sprintf("$%d\r\n%s\r\n", len(name), name), false
+		}
+		return "+OK\r\n", false
+	})
+}
+
+func serverAddr(s *droppingServer) string {
+	return s.listener.Addr().String()
+}
+
+// eventually waits up to a second for cond to hold.
+func eventually(t *testing.T, cond func() bool, format string, args ...interface{}) {
+	for deadline := time.Now().Add(time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf(format, args...)
+		}
+	}
+}
+
+// getFrom returns the reply to GET, which is the name of the namedServer that replied.
+func getFrom(redis *Redis) string {
+	value, err := redis.Get("key")
+	if err != nil {
+		return err.Error()
+	}
+	return string(value)
+}
+
+func TestDialSentinel(t *testing.T) {
+	a, b := namedServer(t, "a"), namedServer(t, "b")
+	s := newFakeSentinel(t, serverAddr(a))
+	redis, err := DialSentinel("mymaster", []string{s.listener.Addr().String()}, &DialConfig{Database: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.ClosePool()
+	if name := getFrom(redis); name != "a" {
+		t.Errorf("GET was executed by %s, expected a", name)
+	}
+	if received := a.received(); received[0] != "SELECT 1" {
+		t.Errorf("master received %q", received)
+	}
+
+	eventually(t, s.subscribed, "client did not subscribe to +switch-master")
+	s.failover(serverAddr(b))
+	eventually(t, func() bool { return getFrom(redis) == "b" }, "client did not follow the failover")
+	if stats := redis.PoolStats(); stats.InUse != 0 || stats.Idle != 1 {
+		t.Errorf("pool of the new master: %+v", stats)
+	}
+
+	redis.ClosePool()
+	if _, err := redis.Get("key"); err == nil {
+		t.Error("GET succeeded after ClosePool")
+	}
+}
+
+func TestDialSentinelErrors(t *testing.T) {
+	a := namedServer(t, "a")
+	s := newFakeSentinel(t, serverAddr(a))
+	if _, err := DialSentinel("mymaster", nil, nil); err == nil {
+		t.Error("DialSentinel with no sentinels succeeded")
+	}
+	if _, err := DialSentinel("othermaster", []string{s.listener.Addr().String()}, nil); err == nil {
+		t.Error("DialSentinel of an unknown master succeeded")
+	}
+	if _, err := DialSentinel("mymaster", []string{closedAddress(t)}, nil); err == nil {
+		t.Error("DialSentinel with no sentinel up succeeded")
+	}
+
+	down := newFakeSentinel(t, closedAddress(t))
+	if _, err := DialSentinel("mymaster", []string{down.listener.Addr().String()}, nil); err == nil {
+		t.Error("DialSentinel of a master that is down succeeded")
+	}
+
+	// The first sentinel is down: the second is asked.
+	redis, err := DialSentinel("mymaster", []string{closedAddress(t), s.listener.Addr().String()}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.ClosePool()
+	if name := getFrom(redis); name != "a" {
+		t.Errorf("GET was executed by %s, expected a", name)
+	}
+}
+
+// closedAddress returns an address at which nothing listens.
+func closedAddress(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return addr
+}
+
+func TestSentinelDown(t *testing.T) {
+	a, b := namedServer(t, "a"), namedServer(t, "b")
+	s1 := newFakeSentinel(t, serverAddr(a))
+	s2 := newFakeSentinel(t, serverAddr(a))
+	redis, err := DialSentinel("mymaster", []string{s1.listener.Addr().String(), s2.listener.Addr().String()}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.ClosePool()
+	eventually(t, s1.subscribed, "client did not subscribe to the first sentinel")
+
+	// The master fails over while the client is subscribed to the first sentinel, which goes down:
+	// the client misses +switch-master, but discovers the new master from the second.
+	s2.failover(serverAddr(b))
+	s1.stop()
+	eventually(t, s2.subscribed, "client did not subscribe to the second sentinel")
+	eventually(t, func() bool { return getFrom(redis) == "b" }, "client did not discover the failover")
+}
+
+func TestSentinelReadFromReplicas(t *testing.T) {
+	a, r1, r2, r3 := namedServer(t, "a"), namedServer(t, "r1"), namedServer(t, "r2"), namedServer(t, "r3")
+	s := newFakeSentinel(t, serverAddr(a),
+		serverAddr(r1)+" slave ok",
+		serverAddr(r2)+" slave,s_down err",
+		serverAddr(r3)+" slave,disconnected ok")
+	redis, err := DialSentinel("mymaster", []string{s.listener.Addr().String()}, &DialConfig{ReadFromReplicas: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.ClosePool()
+	for i := 0; i < 3; i++ {
+		if name := getFrom(redis); name != "r1" {
+			t.Errorf("GET was executed by %s, expected r1, the only replica that is up", name)
+		}
+	}
+	if err := redis.Set("key", "value", 0, 0, false, false); err != nil {
+		t.Error(err)
+	}
+	if received := a.received(); len(received) != 1 || received[0] != "SET key value" {
+		t.Errorf("master received %q, expected SET", received)
+	}
+	pipelined, err := redis.Pipelining()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipelined.Command("GET", "key")
+	if replies, err := pipelined.ReceiveAll(); err != nil || string(replies[0].Bulk) != "a" {
+		t.Errorf("pipelined GET returned %v, %v; expected the master's reply", replies, err)
+	}
+	pipelined.Close()
+
+	// A replica comes up: reads are spread over both.
+	eventually(t, s.subscribed, "client did not subscribe")
+	s.setReplicas(serverAddr(r1)+" slave ok", serverAddr(r3)+" slave ok")
+	eventually(t, func() bool { return getFrom(redis) == "r3" }, "client did not read from the new replica")
+	if first, second := getFrom(redis), getFrom(redis); first == second {
+		t.Errorf("GETs were both executed by %s", first)
+	}
+
+	// All replicas go down: reads go to the master.
+	s.setReplicas()
+	eventually(t, func() bool { return getFrom(redis) == "a" }, "client did not read from the master")
+}
@@ -188,26 +188,31 @@ func (r *Redis) LastSave() (int64, error) {
 // MonitorCommand is a debugging command that streams back every command processed by the Redis server.
 type MonitorCommand struct {
 	redis *Redis
+	pool  *connPool
 	conn  *connection
 }
 
 // Monitor sned MONITOR command to redis server.
 func (r *Redis) Monitor() (*MonitorCommand, error) {
-	c, err := r.pool.Get()
+	pool := r.masterPool()
+	c, err := pool.Get()
 	if err != nil {
 		return nil, err
 	}
 	if err := c.SendCommand("MONITOR"); err != nil {
+		pool.Remove(c)
 		return nil, err
 	}
 	rp, err := c.RecvReply()
 	if err != nil {
+		pool.Remove(c)
 		return nil, err
 	}
 	if err := rp.OKValue(); err != nil {
+		pool.Remove(c)
 		return nil, err
 	}
-	return &MonitorCommand{r, c}, nil
+	return &MonitorCommand{r, pool, c}, nil
 }
 
 // Receive read from redis server and return the reply.
@@ -220,8 +225,11 @@ func (m *MonitorCommand) Receive() (string, error) {
 }
 
 // Close closes current monitor command.
+// QUIT ends the monitoring, and the connection, which is then removed from the pool.
 func (m *MonitorCommand) Close() error {
-	return m.conn.SendCommand("QUIT")
+	err := m.conn.SendCommand("QUIT")
+	m.pool.Remove(m.conn)
+	return err
 }
 
 // Save performs a synchronous save of the dataset
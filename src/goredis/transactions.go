@@ -1,6 +1,7 @@
 package goredis
 
 import (
+	"context"
 	"errors"
 )
 
@@ -10,60 +11,88 @@ import (
 // so everything you can do with a Redis transaction, you can also do with a script,
 // and usually the script will be both simpler and faster.
 type Transaction struct {
-	redis *Redis
-	conn  *connection
+	redis   *Redis
+	pool    *connPool
+	conn    *connection
+	ctx     context.Context
+	release func()
+	broken  bool
+	multi   bool // MULTI has been sent, and neither EXEC nor DISCARD since
 }
 
 // Transaction new a *transaction from *redis
 func (r *Redis) Transaction() (*Transaction, error) {
-	c, err := r.pool.Get()
+	return r.TransactionContext(r.context())
+}
+
+// TransactionContext new a *transaction from *redis, bound to ctx until it is closed:
+// if ctx ends, the command in progress fails with ctx's error.
+func (r *Redis) TransactionContext(ctx context.Context) (*Transaction, error) {
+	pool := r.masterPool()
+	c, err := pool.GetContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if err := c.SendCommand("MULTI"); err != nil {
-		r.pool.Put(c)
-		return nil, err
-	}
-	if _, err := c.RecvReply(); err != nil {
-		r.pool.Put(c)
+	t := &Transaction{redis: r, pool: pool, conn: c, ctx: ctx, release: c.bind(ctx)}
+	if _, err := t.execute("MULTI"); err != nil {
+		t.Close()
 		return nil, err
 	}
-	return &Transaction{r, c}, nil
+	t.multi = true
+	return t, nil
 }
 
-// Close closes the transaction, put the under connection back for reuse
+// Close closes the transaction, put the under connection back for reuse,
+// unless an I/O error, or ctx ending, has left it in an unknown state, or the transaction is still open
+// (neither Exec nor Discard succeeded), in which case it is closed.
 func (t *Transaction) Close() {
-	t.redis.pool.Put(t.conn)
+	t.release()
+	if t.broken || t.multi {
+		t.pool.Remove(t.conn)
+	} else {
+		t.pool.Put(t.conn)
+	}
+}
+
+// execute sends a command on the transaction's connection and receives its reply.
+func (t *Transaction) execute(args ...interface{}) (*Reply, error) {
+	if err := t.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := t.conn.SendCommand(args...); err != nil {
+		t.broken = true
+		return nil, ctxErr(t.ctx, err)
+	}
+	rp, err := t.conn.RecvReply()
+	if err != nil {
+		t.broken = true
+		return nil, ctxErr(t.ctx, err)
+	}
+	return rp, nil
 }
 
 // Discard flushes all previously queued commands in a transaction
 // and restores the connection state to normal.
 // If WATCH was used, DISCARD unwatches all keys.
 func (t *Transaction) Discard() error {
-	if err := t.conn.SendCommand("DISCARD"); err != nil {
-		return err
+	_, err := t.execute("DISCARD")
+	if err == nil {
+		t.multi = false
 	}
-	_, err := t.conn.RecvReply()
 	return err
 }
 
 // Watch marks the given keys to be watched for conditional execution of a transaction.
 func (t *Transaction) Watch(keys ...string) error {
 	args := packArgs("WATCH", keys)
-	if err := t.conn.SendCommand(args...); err != nil {
-		return err
-	}
-	_, err := t.conn.RecvReply()
+	_, err := t.execute(args...)
 	return err
 }
 
 // UnWatch flushes all the previously watched keys for a transaction.
 // If you call EXEC or DISCARD, there's no need to manually call UNWATCH.
 func (t *Transaction) UnWatch() error {
-	if err := t.conn.SendCommand("UNWATCH"); err != nil {
-		return err
-	}
-	_, err := t.conn.RecvReply()
+	_, err := t.execute("UNWATCH")
 	return err
 }
 
@@ -72,13 +101,11 @@ func (t *Transaction) UnWatch() error {
 // When using WATCH, EXEC will execute commands only if the watched keys were not modified,
 // allowing for a check-and-set mechanism.
 func (t *Transaction) Exec() ([]*Reply, error) {
-	if err := t.conn.SendCommand("EXEC"); err != nil {
-		return nil, err
-	}
-	rp, err := t.conn.RecvReply()
+	rp, err := t.execute("EXEC")
 	if err != nil {
 		return nil, err
 	}
+	t.multi = false
 	return rp.MultiValue()
 }
 
@@ -86,10 +113,7 @@ func (t *Transaction) Exec() ([]*Reply, error) {
 // and redis will return QUEUED back
 func (t *Transaction) Command(args ...interface{}) error {
 	args2 := packArgs(args...)
-	if err := t.conn.SendCommand(args2...); err != nil {
-		return err
-	}
-	rp, err := t.conn.RecvReply()
+	rp, err := t.execute(args2...)
 	if err != nil {
 		return err
 	}
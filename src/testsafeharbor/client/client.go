@@ -0,0 +1,260 @@
+/*******************************************************************************
+ * A typed client for the SafeHarbor REST API. Each method sends one request and
+ * returns the response as a desc (see descs.go), or an error. An error is
+ * returned - rather than a panic occurring - if the server refuses the request
+ * (ServerError), or if the response lacks a field that the desc requires or has
+ * a field of the wrong type (ResponseError).
+ *
+ * Requests are sent through a Sender, which helpers.TestContext implements, so
+ * that they can be recorded and replayed.
+ */
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"encoding/json"
+)
+
+/*******************************************************************************
+ * Sends requests to the server. The methods are those of rest.RestContext.
+ */
+type Sender interface {
+	SendSessionGet(sessionId string, reqName string, names []string,
+		values []string) (*http.Response, error)
+	SendSessionPost(sessionId string, reqName string, names []string,
+		values []string) (*http.Response, error)
+	SendSessionFilePost(sessionId string, reqName string, names []string,
+		values []string, path string) (*http.Response, error)
+}
+
+type Client struct {
+	Sender Sender
+	SessionId string  // "" if not authenticated
+	Log string  // if not "", sent as the Log field of each request
+}
+
+func NewClient(sender Sender, sessionId, log string) *Client {
+	return &Client{
+		Sender: sender,
+		SessionId: sessionId,
+		Log: log,
+	}
+}
+
+/*******************************************************************************
+ * Returned when the server responds with a status other than 200.
+ */
+type ServerError struct {
+	ReqName string
+	StatusCode int
+	Status string
+	Reason string  // the HTTPReasonPhrase of the response, or its body
+}
+
+func (err *ServerError) Error() string {
+	return fmt.Sprintf("%s: response status %s: %s", err.ReqName, err.Status, err.Reason)
+}
+
+/*******************************************************************************
+ * Returned when a response has a status of 200 but is not the expected desc.
+ */
+type ResponseError struct {
+	ReqName string
+	Message string
+}
+
+func (err *ResponseError) Error() string {
+	return fmt.Sprintf("%s: invalid response: %s", err.ReqName, err.Message)
+}
+
+/*******************************************************************************
+ * Return true if the error is a ServerError, i.e., the server received the
+ * request and refused it.
+ */
+func IsServerError(err error) bool {
+	var _, isType = err.(*ServerError)
+	return isType
+}
+
+/*******************************************************************************
+ * Return the HTTP status of a ServerError, or 0 for any other error.
+ */
+func GetStatusCode(err error) int {
+	if serverErr, isType := err.(*ServerError); isType { return serverErr.StatusCode }
+	return 0
+}
+
+/*******************************************************************************
+ * Send a request, and return the body of the response. method is "GET",
+ * "POST", or "FILEPOST"; path is the file to post, for "FILEPOST". If the
+ * status of the response is not 200, return a ServerError.
+ */
+func (client *Client) send(method, reqName string, names, values []string,
+	path string) ([]byte, error) {
+	return client.sendAs(client.SessionId, method, reqName, names, values, path)
+}
+
+func (client *Client) sendAs(sessionId, method, reqName string, names, values []string,
+	path string) ([]byte, error) {
+
+	if client.Log != "" {
+		names = append([]string{"Log"}, names...)
+		values = append([]string{client.Log}, values...)
+	}
+	var resp *http.Response
+	var err error
+	switch method {
+	case "GET": resp, err = client.Sender.SendSessionGet(sessionId, reqName, names, values)
+	case "POST": resp, err = client.Sender.SendSessionPost(sessionId, reqName, names, values)
+	case "FILEPOST": resp, err = client.Sender.SendSessionFilePost(sessionId, reqName,
+		names, values, path)
+	default: panic("Unrecognized method: " + method)
+	}
+	if err != nil { return nil, err }
+	if resp == nil { return nil, &ResponseError{ ReqName: reqName, Message: "no response" } }
+
+	var body []byte
+	body, err = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil { return nil, err }
+
+	if resp.StatusCode != 200 {
+		var serverErr = &ServerError{
+			ReqName: reqName,
+			StatusCode: resp.StatusCode,
+			Status: resp.Status,
+			Reason: strings.TrimSpace(string(body)),
+		}
+		var fields map[string]interface{}
+		if json.Unmarshal(body, &fields) == nil {
+			if reason, isType := fields["HTTPReasonPhrase"].(string); isType { serverErr.Reason = reason }
+		}
+		return nil, serverErr
+	}
+	return body, nil
+}
+
+func (client *Client) post(reqName string, names, values []string) ([]byte, error) {
+	return client.send("POST", reqName, names, values, "")
+}
+
+/*******************************************************************************
+ * Post the file, if path is not "". Otherwise, post only the form values.
+ */
+func (client *Client) postWithFile(reqName string, names, values []string,
+	path string) ([]byte, error) {
+	if path == "" { return client.send("POST", reqName, names, values, "") }
+	return client.send("FILEPOST", reqName, names, values, path)
+}
+
+/*******************************************************************************
+ * Parse a response body that is a JSON object into the desc, which must be a
+ * pointer to one of the desc types.
+ */
+func decode(reqName string, body []byte, desc interface{}) error {
+	var fields map[string]interface{}
+	var err = json.Unmarshal(body, &fields)
+	if err != nil { return &ResponseError{ ReqName: reqName, Message: err.Error() } }
+	return decodeFields(reqName, fields, "", desc)
+}
+
+/*******************************************************************************
+ * Parse a response body that is a list ({"payload": [...]}), returning the
+ * fields of each element.
+ */
+func decodeList(reqName string, body []byte) ([]map[string]interface{}, error) {
+	var list struct {
+		Payload []map[string]interface{} `json:"payload"`
+	}
+	var err = json.Unmarshal(body, &list)
+	if err != nil { return nil, &ResponseError{ ReqName: reqName, Message: err.Error() } }
+	if list.Payload == nil { return []map[string]interface{}{}, nil }
+	return list.Payload, nil
+}
+
+/*******************************************************************************
+ * Set the desc from the fields of a JSON object, and check that each field of
+ * the desc that is not optional is present. path identifies the object in
+ * error messages; it is "" for the top level object of a response.
+ */
+func decodeFields(reqName string, fields map[string]interface{}, path string,
+	desc interface{}) error {
+
+	var bytes, err = json.Marshal(fields)
+	if err != nil { return &ResponseError{ ReqName: reqName, Message: err.Error() } }
+	err = json.Unmarshal(bytes, desc)
+	if err != nil { return &ResponseError{ ReqName: reqName, Message: path + err.Error() } }
+	err = checkFields(reflect.TypeOf(desc), fields, path)
+	if err != nil { return &ResponseError{ ReqName: reqName, Message: err.Error() } }
+	if holder, isType := desc.(fieldsHolder); isType { holder.setFields(fields) }
+	return nil
+}
+
+/*******************************************************************************
+ * Check that the value, parsed from JSON, has each field that the type
+ * requires, recursively. A field is required unless it is tagged
+ * client:"optional". A null value counts as missing.
+ */
+func checkFields(t reflect.Type, value interface{}, path string) error {
+	switch t.Kind() {
+	case reflect.Ptr: return checkFields(t.Elem(), value, path)
+	case reflect.Slice:
+		var elements, isType = value.([]interface{})
+		if ! isType { return nil }
+		for i, element := range elements {
+			var err = checkFields(t.Elem(), element, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil { return err }
+		}
+	case reflect.Struct:
+		var fields, isType = value.(map[string]interface{})
+		if ! isType { return nil }
+		for i := 0; i < t.NumField(); i++ {
+			var field = t.Field(i)
+			if field.PkgPath != "" { continue }  // unexported
+			var name = strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" { continue }
+			if field.Anonymous && (name == "") {
+				var err = checkFields(field.Type, value, path)
+				if err != nil { return err }
+				continue
+			}
+			if name == "" { name = field.Name }
+			var fieldPath = name
+			if path != "" { fieldPath = path + "." + name }
+			var fieldValue = fields[name]
+			if fieldValue == nil {
+				if field.Tag.Get("client") == "optional" { continue }
+				return fmt.Errorf("field %s is missing", fieldPath)
+			}
+			var err = checkFields(field.Type, fieldValue, fieldPath)
+			if err != nil { return err }
+		}
+	}
+	return nil
+}
+
+/*******************************************************************************
+ * Encode build parameters in the form that the server expects:
+ * name1:value1;name2:value2...
+ */
+func encodeParams(paramNames, paramValues []string) (string, error) {
+	if len(paramNames) != len(paramValues) {
+		return "", fmt.Errorf("%d parameter names, but %d values", len(paramNames), len(paramValues))
+	}
+	var paramStr string = ""
+	for i, paramName := range paramNames {
+		if i > 0 { paramStr = paramStr + ";" }
+		paramStr = paramStr + fmt.Sprintf("%s:%s", paramName, paramValues[i])
+	}
+	return paramStr, nil
+}
+
+func boolToString(b bool) string {
+	if b { return "true" }
+	return "false"
+}
@@ -0,0 +1,252 @@
+/*******************************************************************************
+ * The descs that the SafeHarbor server returns. A field must be present in a
+ * response unless it is tagged client:"optional". Each desc also retains all of
+ * the fields of the response, including any that the desc does not define, in
+ * Fields.
+ */
+
+package client
+
+type BaseDesc struct {
+	HTTPStatusCode int `client:"optional"`
+	HTTPReasonPhrase string `client:"optional"`
+	ObjectType string `client:"optional"`
+	Fields map[string]interface{} `json:"-"`  // all fields of the response, as parsed
+}
+
+type fieldsHolder interface {
+	setFields(fields map[string]interface{})
+}
+
+func (desc *BaseDesc) setFields(fields map[string]interface{}) {
+	desc.Fields = fields
+}
+
+/*******************************************************************************
+ * The response to a request that does not return an object.
+ */
+type Result struct {
+	HTTPStatusCode int
+	HTTPReasonPhrase string
+	ObjectType string `client:"optional"`
+	Fields map[string]interface{} `json:"-"`
+}
+
+func (result *Result) setFields(fields map[string]interface{}) {
+	result.Fields = fields
+}
+
+type SessionToken struct {
+	BaseDesc
+	UniqueSessionId string
+	AuthenticatedUserid string
+	RealmId string `client:"optional"`
+	IsAdmin bool
+}
+
+type RealmDesc struct {
+	BaseDesc
+	Id string
+	Name string
+	OrgFullName string
+	AdminUserId string
+	Description string `client:"optional"`
+	CreationDate string `client:"optional"`
+}
+
+type UserDesc struct {
+	BaseDesc
+	Id string
+	UserId string
+	Name string
+	EmailAddress string `client:"optional"`
+	RealmId string
+	Enabled bool `client:"optional"`
+	DefaultRepoId string `client:"optional"`
+	CanModifyTheseRealms []string
+}
+
+type GroupDesc struct {
+	BaseDesc
+	Id string
+	RealmId string
+	Name string
+	Description string
+	CreationDate string
+}
+
+type RepoDesc struct {
+	BaseDesc
+	Id string
+	RealmId string
+	Name string
+	Description string
+	CreationDate string
+	DockerfileIds []string
+}
+
+type ParameterValueDesc struct {
+	Name string
+	Value string
+}
+
+type DockerfileDesc struct {
+	BaseDesc
+	Id string
+	RepoId string
+	Name string
+	Description string `client:"optional"`
+	CreationDate string `client:"optional"`
+	ParameterValueDescs []ParameterValueDesc `client:"optional"`
+}
+
+type DockerImageDesc struct {
+	BaseDesc
+	ObjId string
+	RepoId string `client:"optional"`
+	Name string
+	Description string `client:"optional"`
+	CreationDate string `client:"optional"`
+	ScanConfigIds []string `client:"optional"`
+}
+
+type DockerImageVersionDesc struct {
+	BaseDesc
+	ObjId string
+	Version string
+	ImageObjId string
+	ImageName string `client:"optional"`
+	RepoId string `client:"optional"`
+	ImageCreationEventId string
+	CreationDate string
+	Digest []int `client:"optional"`
+	Signature []int `client:"optional"`
+	ScanEventIds []string `client:"optional"`
+	DockerBuildOutput string `client:"optional"`
+}
+
+type ScanConfigDesc struct {
+	BaseDesc
+	Id string
+	RepoId string `client:"optional"`
+	Name string `client:"optional"`
+	Description string `client:"optional"`
+	ProviderName string
+	SuccessExpression string `client:"optional"`
+	FlagId string `client:"optional"`
+	ScanParameterValueDescs []ParameterValueDesc
+	DockerImagesIdsThatUse []string `client:"optional"`
+}
+
+type FlagDesc struct {
+	BaseDesc
+	FlagId string
+	RepoId string
+	Name string
+	Description string `client:"optional"`
+	ImageURL string
+}
+
+/*******************************************************************************
+ * Permissions, in the order used by the Try*Permission methods of
+ * helpers.TestContext: create-in, read, write, execute, delete.
+ */
+type PermissionMask struct {
+	CanCreateIn bool
+	CanRead bool
+	CanWrite bool
+	CanExecute bool
+	CanDelete bool
+}
+
+func NewPermissionMask(permissions []bool) PermissionMask {
+	var p = make([]bool, 5)
+	copy(p, permissions)
+	return PermissionMask{
+		CanCreateIn: p[0],
+		CanRead: p[1],
+		CanWrite: p[2],
+		CanExecute: p[3],
+		CanDelete: p[4],
+	}
+}
+
+func (mask PermissionMask) ToSlice() []bool {
+	return []bool{mask.CanCreateIn, mask.CanRead, mask.CanWrite, mask.CanExecute, mask.CanDelete}
+}
+
+type PermissionDesc struct {
+	BaseDesc
+	ACLEntryId string `client:"optional"`  // absent if the party has no permissions
+	PartyId string
+	ResourceId string
+	PermissionMask
+}
+
+/*******************************************************************************
+ * An event of any kind. Which of the optional fields are present depends on
+ * the ObjectType: DockerfileExecEventDesc or ScanEventDesc.
+ */
+type EventDesc struct {
+	BaseDesc
+	Id string
+	When string `client:"optional"`
+	UserObjId string `client:"optional"`
+	// Dockerfile exec events:
+	DockerfileId string `client:"optional"`
+	ParameterValues []ParameterValueDesc `client:"optional"`
+	DockerfileContent string `client:"optional"`
+	// Both kinds:
+	ImageVersionObjId string `client:"optional"`
+	// Scan events:
+	ScanConfigId string `client:"optional"`
+	ProviderName string `client:"optional"`
+	ParameterValueDescs []ParameterValueDesc `client:"optional"`
+	Score string `client:"optional"`
+	VulnerabilityDescs []VulnerabilityDesc `client:"optional"`
+}
+
+type VulnerabilityDesc struct {
+	VCE_ID string
+	Description string `client:"optional"`
+}
+
+type ScanEventDesc struct {
+	BaseDesc
+	Id string
+	When string
+	UserObjId string
+	ScanConfigId string
+	ProviderName string `client:"optional"`
+	ParameterValueDescs []ParameterValueDesc `client:"optional"`
+	ImageVersionObjId string `client:"optional"`
+	Score string
+	VulnerabilityDescs []VulnerabilityDesc
+}
+
+/*******************************************************************************
+ * The most recent scan of an image. All fields are empty if the image has not
+ * been scanned.
+ */
+type ImageStatusDesc struct {
+	BaseDesc
+	EventId string `client:"optional"`
+	When string `client:"optional"`
+	UserObjId string `client:"optional"`
+	ScanConfigId string `client:"optional"`
+	ProviderName string `client:"optional"`
+	ParameterValueDescs []ParameterValueDesc `client:"optional"`
+	Score string `client:"optional"`
+}
+
+type ScanParameterDesc struct {
+	Name string
+	Description string `client:"optional"`
+}
+
+type ScanProviderDesc struct {
+	BaseDesc
+	Name string
+	Description string `client:"optional"`
+	Parameters []ScanParameterDesc
+}
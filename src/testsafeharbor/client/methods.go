@@ -0,0 +1,749 @@
+/*******************************************************************************
+ * One method per SafeHarbor REST request. Request and field names are those of
+ * the SafeHarbor API.
+ */
+
+package client
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"bytes"
+	"reflect"
+)
+
+/*******************************************************************************
+ * Post a request, and parse the response into the desc.
+ */
+func (client *Client) postFor(desc interface{}, reqName string, names, values []string) error {
+	var body, err = client.post(reqName, names, values)
+	if err != nil { return err }
+	return decode(reqName, body, desc)
+}
+
+/*******************************************************************************
+ * Post a request, with the file if path is not "", and parse the response into
+ * the desc.
+ */
+func (client *Client) postWithFileFor(desc interface{}, reqName string, names, values []string,
+	path string) error {
+	var body, err = client.postWithFile(reqName, names, values, path)
+	if err != nil { return err }
+	return decode(reqName, body, desc)
+}
+
+/*******************************************************************************
+ * Post a request that returns a list, and parse the elements into descs. descs
+ * must be a pointer to a slice of pointers to one of the desc types.
+ */
+func (client *Client) postForList(descs interface{}, reqName string, names, values []string) error {
+	var body, err = client.post(reqName, names, values)
+	if err != nil { return err }
+	var elements []map[string]interface{}
+	elements, err = decodeList(reqName, body)
+	if err != nil { return err }
+	var sliceValue = reflect.ValueOf(descs).Elem()
+	var descType = sliceValue.Type().Elem().Elem()
+	for i, fields := range elements {
+		var desc = reflect.New(descType)
+		err = decodeFields(reqName, fields, fmt.Sprintf("payload[%d]", i), desc.Interface())
+		if err != nil { return err }
+		sliceValue.Set(reflect.Append(sliceValue, desc))
+	}
+	return nil
+}
+
+/*******************************************************************************
+ * Post a request that returns a Result.
+ */
+func (client *Client) postForResult(reqName string, names, values []string) (*Result, error) {
+	var result = &Result{}
+	var err = client.postFor(result, reqName, names, values)
+	if err != nil { return nil, err }
+	return result, nil
+}
+
+/*******************************************************************************
+ * Post a request that returns the content of a file, and write it to the
+ * specified file. Return the number of bytes written.
+ */
+func (client *Client) postForFile(filename string, reqName string, names, values []string) (
+	int64, error) {
+	var body, err = client.post(reqName, names, values)
+	if err != nil { return 0, err }
+	var file *os.File
+	file, err = os.Create(filename)
+	if err != nil { return 0, err }
+	defer file.Close()
+	return io.Copy(file, bytes.NewReader(body))
+}
+
+// -----------------------------------------------------------------------------
+// Session and server.
+
+func (client *Client) Ping() error {
+	var _, err = client.sendAs("", "GET", "ping", nil, nil, "")
+	return err
+}
+
+/*******************************************************************************
+ * Discard all of the server's data. Only a server in debug mode allows this.
+ */
+func (client *Client) ClearAll() error {
+	var _, err = client.sendAs("", "GET", "clearAll", nil, nil, "")
+	return err
+}
+
+/*******************************************************************************
+ * If successful, the client's SessionId is set to that of the new session.
+ */
+func (client *Client) Authenticate(userId, pswd string) (*SessionToken, error) {
+	var token = &SessionToken{}
+	var err = client.postFor(token, "authenticate",
+		[]string{"UserId", "Password"}, []string{userId, pswd})
+	if err != nil { return nil, err }
+	client.SessionId = token.UniqueSessionId
+	return token, nil
+}
+
+func (client *Client) Logout() (*Result, error) {
+	var result, err = client.postForResult("logout", nil, nil)
+	if err != nil { return nil, err }
+	client.SessionId = ""
+	return result, nil
+}
+
+// -----------------------------------------------------------------------------
+// Realms.
+
+/*******************************************************************************
+ * Create a realm and its admin user, without being authenticated. Returns the
+ * desc of the user.
+ */
+func (client *Client) CreateRealmAnon(realmName, orgFullName, adminUserId,
+	adminUserName, adminEmailAddr, adminPassword string) (*UserDesc, error) {
+	var desc = &UserDesc{}
+	var err = client.postFor(desc, "createRealmAnon",
+		[]string{"UserId", "UserName", "EmailAddress", "Password", "RealmName", "OrgFullName"},
+		[]string{adminUserId, adminUserName, adminEmailAddr, adminPassword, realmName, orgFullName})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+func (client *Client) CreateRealm(realmName, orgFullName, desc string) (*RealmDesc, error) {
+	var realmDesc = &RealmDesc{}
+	var err = client.postFor(realmDesc, "createRealm",
+		[]string{"RealmName", "OrgFullName", "Description"},
+		[]string{realmName, orgFullName, desc})
+	if err != nil { return nil, err }
+	return realmDesc, nil
+}
+
+func (client *Client) GetRealmDesc(realmId string) (*RealmDesc, error) {
+	var desc = &RealmDesc{}
+	var err = client.postFor(desc, "getRealmDesc", []string{"RealmId"}, []string{realmId})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+func (client *Client) GetRealmByName(realmName string) (*RealmDesc, error) {
+	var desc = &RealmDesc{}
+	var err = client.postFor(desc, "getRealmByName", []string{"RealmName"}, []string{realmName})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+func (client *Client) GetAllRealms() ([]*RealmDesc, error) {
+	var descs = []*RealmDesc{}
+	var err = client.postForList(&descs, "getAllRealms", nil, nil)
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+func (client *Client) GetRealmUsers(realmId string) ([]*UserDesc, error) {
+	var descs = []*UserDesc{}
+	var err = client.postForList(&descs, "getRealmUsers", []string{"RealmId"}, []string{realmId})
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+func (client *Client) GetRealmGroups(realmId string) ([]*GroupDesc, error) {
+	var descs = []*GroupDesc{}
+	var err = client.postForList(&descs, "getRealmGroups", []string{"RealmId"}, []string{realmId})
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+func (client *Client) GetRealmRepos(realmId string) ([]*RepoDesc, error) {
+	var descs = []*RepoDesc{}
+	var err = client.postForList(&descs, "getRealmRepos", []string{"RealmId"}, []string{realmId})
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+func (client *Client) RemRealmUser(realmId, userObjId string) (*Result, error) {
+	return client.postForResult("remRealmUser",
+		[]string{"RealmId", "UserObjId"}, []string{realmId, userObjId})
+}
+
+func (client *Client) DeactivateRealm(realmId string) (*Result, error) {
+	return client.postForResult("deactivateRealm", []string{"RealmId"}, []string{realmId})
+}
+
+// -----------------------------------------------------------------------------
+// Users.
+
+func (client *Client) CreateUser(userId, userName, email, pswd, realmId string) (*UserDesc, error) {
+	var desc = &UserDesc{}
+	var err = client.postFor(desc, "createUser",
+		[]string{"UserId", "UserName", "EmailAddress", "Password", "RealmId"},
+		[]string{userId, userName, email, pswd, realmId})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+func (client *Client) GetUserDesc(userId string) (*UserDesc, error) {
+	var desc = &UserDesc{}
+	var err = client.postFor(desc, "getUserDesc", []string{"UserId"}, []string{userId})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+func (client *Client) GetMyDesc() (*UserDesc, error) {
+	var desc = &UserDesc{}
+	var err = client.postFor(desc, "getMyDesc", nil, nil)
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+func (client *Client) DisableUser(userObjId string) (*Result, error) {
+	return client.postForResult("disableUser", []string{"UserObjId"}, []string{userObjId})
+}
+
+func (client *Client) ReenableUser(userObjId string) (*Result, error) {
+	return client.postForResult("reenableUser", []string{"UserObjId"}, []string{userObjId})
+}
+
+func (client *Client) UpdateUserInfo(userId, userName, email string) (*UserDesc, error) {
+	var desc = &UserDesc{}
+	var err = client.postFor(desc, "updateUserInfo",
+		[]string{"UserId", "UserName", "EmailAddress"}, []string{userId, userName, email})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+func (client *Client) UserExists(userId string) (*Result, error) {
+	return client.postForResult("userExists", []string{"UserId"}, []string{userId})
+}
+
+func (client *Client) ChangePassword(userId, oldPswd, newPswd string) (*Result, error) {
+	return client.postForResult("changePassword",
+		[]string{"UserId", "OldPassword", "NewPassword"}, []string{userId, oldPswd, newPswd})
+}
+
+func (client *Client) MoveUserToRealm(userObjId, realmId string) (*Result, error) {
+	return client.postForResult("moveUserToRealm",
+		[]string{"UserObjId", "RealmId"}, []string{userObjId, realmId})
+}
+
+func (client *Client) EnableEmailVerification(enabled bool) (*Result, error) {
+	return client.postForResult("enableEmailVerification",
+		[]string{"VerificationEnabled"}, []string{boolToString(enabled)})
+}
+
+func (client *Client) ValidateAccountVerificationToken(token string) (*Result, error) {
+	return client.postForResult("validateAccountVerificationToken",
+		[]string{"AccountVerificationToken"}, []string{token})
+}
+
+// -----------------------------------------------------------------------------
+// Groups.
+
+func (client *Client) CreateGroup(realmId, name, desc string, addMe bool) (*GroupDesc, error) {
+	var groupDesc = &GroupDesc{}
+	var err = client.postFor(groupDesc, "createGroup",
+		[]string{"RealmId", "Name", "Description", "AddMe"},
+		[]string{realmId, name, desc, boolToString(addMe)})
+	if err != nil { return nil, err }
+	return groupDesc, nil
+}
+
+func (client *Client) GetGroupDesc(groupId string) (*GroupDesc, error) {
+	var desc = &GroupDesc{}
+	var err = client.postFor(desc, "getGroupDesc", []string{"GroupId"}, []string{groupId})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+func (client *Client) GetGroupUsers(groupId string) ([]*UserDesc, error) {
+	var descs = []*UserDesc{}
+	var err = client.postForList(&descs, "getGroupUsers", []string{"GroupId"}, []string{groupId})
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+func (client *Client) AddGroupUser(groupId, userObjId string) (*Result, error) {
+	return client.postForResult("addGroupUser",
+		[]string{"GroupId", "UserObjId"}, []string{groupId, userObjId})
+}
+
+func (client *Client) RemGroupUser(groupId, userObjId string) (*Result, error) {
+	return client.postForResult("remGroupUser",
+		[]string{"GroupId", "UserObjId"}, []string{groupId, userObjId})
+}
+
+func (client *Client) DeleteGroup(groupId string) (*Result, error) {
+	return client.postForResult("deleteGroup", []string{"GroupId"}, []string{groupId})
+}
+
+// -----------------------------------------------------------------------------
+// The authenticated user's own resources.
+
+func (client *Client) GetMyGroups() ([]*GroupDesc, error) {
+	var descs = []*GroupDesc{}
+	var err = client.postForList(&descs, "getMyGroups", nil, nil)
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+func (client *Client) GetMyRealms() ([]*RealmDesc, error) {
+	var descs = []*RealmDesc{}
+	var err = client.postForList(&descs, "getMyRealms", nil, nil)
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+func (client *Client) GetMyRepos() ([]*RepoDesc, error) {
+	var descs = []*RepoDesc{}
+	var err = client.postForList(&descs, "getMyRepos", nil, nil)
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+func (client *Client) GetMyDockerfiles() ([]*DockerfileDesc, error) {
+	var descs = []*DockerfileDesc{}
+	var err = client.postForList(&descs, "getMyDockerfiles", nil, nil)
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+func (client *Client) GetMyDockerImages() ([]*DockerImageDesc, error) {
+	var descs = []*DockerImageDesc{}
+	var err = client.postForList(&descs, "getMyDockerImages", nil, nil)
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+func (client *Client) GetMyScanConfigs() ([]*ScanConfigDesc, error) {
+	var descs = []*ScanConfigDesc{}
+	var err = client.postForList(&descs, "getMyScanConfigs", nil, nil)
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+func (client *Client) GetMyFlags() ([]*FlagDesc, error) {
+	var descs = []*FlagDesc{}
+	var err = client.postForList(&descs, "getMyFlags", nil, nil)
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+// -----------------------------------------------------------------------------
+// Repos and dockerfiles.
+
+/*******************************************************************************
+ * If optDockerfilePath is not "", the dockerfile is added to the new repo.
+ */
+func (client *Client) CreateRepo(realmId, name, desc, optDockerfilePath string) (*RepoDesc, error) {
+	var repoDesc = &RepoDesc{}
+	var err = client.postWithFileFor(repoDesc, "createRepo",
+		[]string{"RealmId", "Name", "Description"}, []string{realmId, name, desc},
+		optDockerfilePath)
+	if err != nil { return nil, err }
+	return repoDesc, nil
+}
+
+func (client *Client) GetRepoDesc(repoId string) (*RepoDesc, error) {
+	var desc = &RepoDesc{}
+	var err = client.postFor(desc, "getRepoDesc", []string{"RepoId"}, []string{repoId})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+func (client *Client) DeleteRepo(repoId string) (*Result, error) {
+	return client.postForResult("deleteRepo", []string{"RepoId"}, []string{repoId})
+}
+
+/*******************************************************************************
+ * If repoId is "", the dockerfile is added to the user's default repo.
+ */
+func (client *Client) AddDockerfile(repoId, dockerfilePath, desc string) (*DockerfileDesc, error) {
+	var dockerfileDesc = &DockerfileDesc{}
+	var body, err = client.send("FILEPOST", "addDockerfile",
+		[]string{"RepoId", "Description"}, []string{repoId, desc}, dockerfilePath)
+	if err != nil { return nil, err }
+	err = decode("addDockerfile", body, dockerfileDesc)
+	if err != nil { return nil, err }
+	return dockerfileDesc, nil
+}
+
+func (client *Client) GetDockerfiles(repoId string) ([]*DockerfileDesc, error) {
+	var descs = []*DockerfileDesc{}
+	var err = client.postForList(&descs, "getDockerfiles", []string{"RepoId"}, []string{repoId})
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+func (client *Client) GetDockerfileDesc(dockerfileId string) (*DockerfileDesc, error) {
+	var desc = &DockerfileDesc{}
+	var err = client.postFor(desc, "getDockerfileDesc",
+		[]string{"DockerfileId"}, []string{dockerfileId})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+func (client *Client) ReplaceDockerfile(dockerfileId, dockerfilePath, desc string) (*Result, error) {
+	var result = &Result{}
+	var body, err = client.send("FILEPOST", "replaceDockerfile",
+		[]string{"DockerfileId", "Description"}, []string{dockerfileId, desc}, dockerfilePath)
+	if err != nil { return nil, err }
+	err = decode("replaceDockerfile", body, result)
+	if err != nil { return nil, err }
+	return result, nil
+}
+
+func (client *Client) RemDockerfile(dockerfileId string) (*Result, error) {
+	return client.postForResult("remDockerfile", []string{"DockerfileId"}, []string{dockerfileId})
+}
+
+/*******************************************************************************
+ * Build an image from a dockerfile that is already in a repo. Returns the desc
+ * of the new image version.
+ */
+func (client *Client) ExecDockerfile(repoId, dockerfileId, imageName string,
+	paramNames, paramValues []string) (*DockerImageVersionDesc, error) {
+	var paramStr, err = encodeParams(paramNames, paramValues)
+	if err != nil { return nil, err }
+	var desc = &DockerImageVersionDesc{}
+	err = client.postFor(desc, "execDockerfile",
+		[]string{"RepoId", "DockerfileId", "ImageName", "Params"},
+		[]string{repoId, dockerfileId, imageName, paramStr})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+/*******************************************************************************
+ * Upload a dockerfile and build an image from it. Returns the desc of the new
+ * image version. The session Id is sent as a form value rather than a cookie.
+ */
+func (client *Client) AddAndExecDockerfile(repoId, desc, imageName, dockerfilePath string,
+	paramNames, paramValues []string) (*DockerImageVersionDesc, error) {
+	var paramStr, err = encodeParams(paramNames, paramValues)
+	if err != nil { return nil, err }
+	var body []byte
+	body, err = client.sendAs("", "FILEPOST", "addAndExecDockerfile",
+		[]string{"RepoId", "Description", "ImageName", "SessionId", "Params"},
+		[]string{repoId, desc, imageName, client.SessionId, paramStr},
+		dockerfilePath)
+	if err != nil { return nil, err }
+	var versionDesc = &DockerImageVersionDesc{}
+	err = decode("addAndExecDockerfile", body, versionDesc)
+	if err != nil { return nil, err }
+	return versionDesc, nil
+}
+
+// -----------------------------------------------------------------------------
+// Images.
+
+func (client *Client) GetDockerImages(repoId string) ([]*DockerImageDesc, error) {
+	var descs = []*DockerImageDesc{}
+	var err = client.postForList(&descs, "getDockerImages", []string{"RepoId"}, []string{repoId})
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+/*******************************************************************************
+ * The Id may be that of an image or of an image version. Exactly one of the
+ * descs returned is non-nil, depending on which it is.
+ */
+func (client *Client) GetDockerImageDesc(dockerImageId string) (
+	*DockerImageDesc, *DockerImageVersionDesc, error) {
+	var reqName = "getDockerImageDesc"
+	var body, err = client.post(reqName, []string{"DockerImageId"}, []string{dockerImageId})
+	if err != nil { return nil, nil, err }
+	var base = &BaseDesc{}
+	err = decode(reqName, body, base)
+	if err != nil { return nil, nil, err }
+	switch base.ObjectType {
+	case "DockerImageDesc":
+		var imageDesc = &DockerImageDesc{}
+		err = decode(reqName, body, imageDesc)
+		if err != nil { return nil, nil, err }
+		return imageDesc, nil, nil
+	case "DockerImageVersionDesc":
+		var versionDesc = &DockerImageVersionDesc{}
+		err = decode(reqName, body, versionDesc)
+		if err != nil { return nil, nil, err }
+		return nil, versionDesc, nil
+	default:
+		return nil, nil, &ResponseError{ ReqName: reqName,
+			Message: "unexpected ObjectType: " + base.ObjectType }
+	}
+}
+
+func (client *Client) GetDockerImageVersions(dockerImageId string) ([]*DockerImageVersionDesc, error) {
+	var descs = []*DockerImageVersionDesc{}
+	var err = client.postForList(&descs, "getDockerImageVersions",
+		[]string{"DockerImageId"}, []string{dockerImageId})
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+func (client *Client) RemDockerImage(imageId string) (*Result, error) {
+	return client.postForResult("remDockerImage", []string{"ImageId"}, []string{imageId})
+}
+
+func (client *Client) RemImageVersion(imageVersionId string) (*Result, error) {
+	return client.postForResult("remImageVersion", []string{"ImageVersionId"}, []string{imageVersionId})
+}
+
+/*******************************************************************************
+ * Download an image (or image version) to the specified file. Returns the size
+ * of the file.
+ */
+func (client *Client) DownloadImage(imageObjId, filename string) (int64, error) {
+	return client.postForFile(filename, "downloadImage", []string{"ImageObjId"}, []string{imageObjId})
+}
+
+func (client *Client) GetDockerImageStatus(imageObjId string) (*ImageStatusDesc, error) {
+	var desc = &ImageStatusDesc{}
+	var err = client.postFor(desc, "getDockerImageStatus", []string{"ImageObjId"}, []string{imageObjId})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+// -----------------------------------------------------------------------------
+// Events.
+
+func (client *Client) GetEventDesc(eventId string) (*EventDesc, error) {
+	var desc = &EventDesc{}
+	var err = client.postFor(desc, "getEventDesc", []string{"EventId"}, []string{eventId})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+func (client *Client) GetUserEvents(userId string) ([]*EventDesc, error) {
+	var descs = []*EventDesc{}
+	var err = client.postForList(&descs, "getUserEvents", []string{"UserId"}, []string{userId})
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+func (client *Client) GetDockerImageEvents(imageObjId string) ([]*EventDesc, error) {
+	var descs = []*EventDesc{}
+	var err = client.postForList(&descs, "getDockerImageEvents",
+		[]string{"ImageObjId"}, []string{imageObjId})
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+func (client *Client) GetDockerfileEvents(dockerfileId string) ([]*EventDesc, error) {
+	var descs = []*EventDesc{}
+	var err = client.postForList(&descs, "getDockerfileEvents",
+		[]string{"DockerfileId"}, []string{dockerfileId})
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+// -----------------------------------------------------------------------------
+// Permissions.
+
+func (client *Client) postPermission(reqName, partyId, resourceId string,
+	mask PermissionMask) (*PermissionDesc, error) {
+	var desc = &PermissionDesc{}
+	var err = client.postFor(desc, reqName,
+		[]string{"PartyId", "ResourceId", "CanCreateIn", "CanRead", "CanWrite", "CanExecute", "CanDelete"},
+		[]string{partyId, resourceId, boolToString(mask.CanCreateIn), boolToString(mask.CanRead),
+			boolToString(mask.CanWrite), boolToString(mask.CanExecute), boolToString(mask.CanDelete)})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+/*******************************************************************************
+ * Replace the party's permissions on the resource.
+ */
+func (client *Client) SetPermission(partyId, resourceId string, mask PermissionMask) (
+	*PermissionDesc, error) {
+	return client.postPermission("setPermission", partyId, resourceId, mask)
+}
+
+/*******************************************************************************
+ * Add to the party's permissions on the resource.
+ */
+func (client *Client) AddPermission(partyId, resourceId string, mask PermissionMask) (
+	*PermissionDesc, error) {
+	return client.postPermission("addPermission", partyId, resourceId, mask)
+}
+
+func (client *Client) GetPermission(partyId, resourceId string) (*PermissionDesc, error) {
+	var desc = &PermissionDesc{}
+	var err = client.postFor(desc, "getPermission",
+		[]string{"PartyId", "ResourceId"}, []string{partyId, resourceId})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+func (client *Client) RemPermission(partyId, resourceId string) (*Result, error) {
+	return client.postForResult("remPermission",
+		[]string{"PartyId", "ResourceId"}, []string{partyId, resourceId})
+}
+
+// -----------------------------------------------------------------------------
+// Scanning.
+
+func (client *Client) GetScanProviders() ([]*ScanProviderDesc, error) {
+	var descs = []*ScanProviderDesc{}
+	var err = client.postForList(&descs, "getScanProviders", nil, nil)
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+/*******************************************************************************
+ * Return the names and values of a scan config request: the standard fields,
+ * followed by the scan provider's parameters. SuccessExpression is sent only
+ * if it is not empty.
+ */
+func scanConfigFields(names, values []string, successExpr string,
+	providerParamNames, providerParamValues []string) ([]string, []string, error) {
+	if len(providerParamNames) != len(providerParamValues) {
+		return nil, nil, fmt.Errorf("%d provider parameter names, but %d values",
+			len(providerParamNames), len(providerParamValues))
+	}
+	if successExpr != "" {
+		names = append(names, "SuccessExpression")
+		values = append(values, successExpr)
+	}
+	names = append(names, providerParamNames...)
+	values = append(values, providerParamValues...)
+	return names, values, nil
+}
+
+/*******************************************************************************
+ * If flagImagePath is not "", it is the image of a new flag for the scan
+ * config. If repoId is "", the scan config is added to the user's default repo.
+ */
+func (client *Client) DefineScanConfig(name, desc, repoId, providerName, successExpr,
+	flagImagePath string, providerParamNames, providerParamValues []string) (
+	*ScanConfigDesc, error) {
+	var names, values, err = scanConfigFields(
+		[]string{"Name", "Description", "RepoId", "ProviderName"},
+		[]string{name, desc, repoId, providerName},
+		successExpr, providerParamNames, providerParamValues)
+	if err != nil { return nil, err }
+	var scanConfigDesc = &ScanConfigDesc{}
+	err = client.postWithFileFor(scanConfigDesc, "defineScanConfig", names, values, flagImagePath)
+	if err != nil { return nil, err }
+	return scanConfigDesc, nil
+}
+
+/*******************************************************************************
+ * Fields that are "" are not changed.
+ */
+func (client *Client) UpdateScanConfig(scanConfigId, name, desc, providerName, successExpr,
+	flagImagePath string, providerParamNames, providerParamValues []string) (
+	*ScanConfigDesc, error) {
+	var names, values, err = scanConfigFields(
+		[]string{"ScanConfigId", "Name", "Description", "ProviderName"},
+		[]string{scanConfigId, name, desc, providerName},
+		successExpr, providerParamNames, providerParamValues)
+	if err != nil { return nil, err }
+	var scanConfigDesc = &ScanConfigDesc{}
+	err = client.postWithFileFor(scanConfigDesc, "updateScanConfig", names, values, flagImagePath)
+	if err != nil { return nil, err }
+	return scanConfigDesc, nil
+}
+
+func (client *Client) GetScanConfigDesc(scanConfigId string) (*ScanConfigDesc, error) {
+	var desc = &ScanConfigDesc{}
+	var err = client.postFor(desc, "getScanConfigDesc", []string{"ScanConfigId"}, []string{scanConfigId})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+func (client *Client) GetScanConfigDescByName(repoId, scanConfigName string) (*ScanConfigDesc, error) {
+	var desc = &ScanConfigDesc{}
+	var err = client.postFor(desc, "getScanConfigDescByName",
+		[]string{"RepoId", "ScanConfigName"}, []string{repoId, scanConfigName})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+func (client *Client) RemScanConfig(scanConfigId string) (*Result, error) {
+	return client.postForResult("remScanConfig", []string{"ScanConfigId"}, []string{scanConfigId})
+}
+
+func (client *Client) UseScanConfigForImage(dockerImageId, scanConfigId string) (*Result, error) {
+	return client.postForResult("useScanConfigForImage",
+		[]string{"DockerImageId", "ScanConfigId"}, []string{dockerImageId, scanConfigId})
+}
+
+func (client *Client) StopUsingScanConfigForImage(dockerImageId, scanConfigId string) (
+	*Result, error) {
+	return client.postForResult("stopUsingScanConfigForImage",
+		[]string{"DockerImageId", "ScanConfigId"}, []string{dockerImageId, scanConfigId})
+}
+
+/*******************************************************************************
+ * Scan an image, or image version, with the specified scan config(s). Returns
+ * one scan event per scan config.
+ */
+func (client *Client) ScanImage(scanConfigId, imageObjId string) ([]*ScanEventDesc, error) {
+	var descs = []*ScanEventDesc{}
+	var err = client.postForList(&descs, "scanImage",
+		[]string{"ScanConfigId", "ImageObjId"}, []string{scanConfigId, imageObjId})
+	if err != nil { return nil, err }
+	return descs, nil
+}
+
+// -----------------------------------------------------------------------------
+// Flags.
+
+/*******************************************************************************
+ * If repoId is "", the flag is added to the user's default repo.
+ */
+func (client *Client) DefineFlag(repoId, flagName, desc, imageFilePath string) (*FlagDesc, error) {
+	var flagDesc = &FlagDesc{}
+	var body, err = client.send("FILEPOST", "defineFlag",
+		[]string{"RepoId", "Name", "Description"}, []string{repoId, flagName, desc}, imageFilePath)
+	if err != nil { return nil, err }
+	err = decode("defineFlag", body, flagDesc)
+	if err != nil { return nil, err }
+	return flagDesc, nil
+}
+
+func (client *Client) GetFlagDesc(flagId string) (*FlagDesc, error) {
+	var desc = &FlagDesc{}
+	var err = client.postFor(desc, "getFlagDesc", []string{"FlagId"}, []string{flagId})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+func (client *Client) GetFlagDescByName(repoId, flagName string) (*FlagDesc, error) {
+	var desc = &FlagDesc{}
+	var err = client.postFor(desc, "getFlagDescByName",
+		[]string{"RepoId", "FlagName"}, []string{repoId, flagName})
+	if err != nil { return nil, err }
+	return desc, nil
+}
+
+/*******************************************************************************
+ * Download the flag's image to the specified file. Returns the size of the file.
+ */
+func (client *Client) GetFlagImage(flagId, filename string) (int64, error) {
+	return client.postForFile(filename, "getFlagImage", []string{"FlagId"}, []string{flagId})
+}
+
+func (client *Client) RemFlag(flagId string) (*Result, error) {
+	return client.postForResult("remFlag", []string{"FlagId"}, []string{flagId})
+}
@@ -0,0 +1,452 @@
+/*******************************************************************************
+ * The objects that the fake server returns, as JSON. Field names are those of
+ * the SafeHarbor server's descriptor types, which the Try* methods of
+ * helpers.TestContext read. Slices are never nil, since the Try* methods expect
+ * JSON arrays rather than null.
+ */
+
+package fakeserver
+
+import (
+	"time"
+	"strings"
+	"crypto/sha256"
+	"crypto/sha512"
+)
+
+type baseDesc struct {
+	HTTPStatusCode int
+	HTTPReasonPhrase string
+	ObjectType string
+}
+
+func newBaseDesc(objectType string) baseDesc {
+	return baseDesc{ HTTPStatusCode: 200, HTTPReasonPhrase: "OK", ObjectType: objectType }
+}
+
+/*******************************************************************************
+ * The response to a request that does not return an object.
+ */
+func newResult(message string) baseDesc {
+	return baseDesc{ HTTPStatusCode: 200, HTTPReasonPhrase: message, ObjectType: "Result" }
+}
+
+/*******************************************************************************
+ * The response to a request that returns a list of objects.
+ */
+type listDesc struct {
+	baseDesc
+	Payload []interface{} `json:"payload"`
+}
+
+func newListDesc() *listDesc {
+	return &listDesc{ baseDesc: newBaseDesc("List"), Payload: make([]interface{}, 0) }
+}
+
+func (list *listDesc) add(obj interface{}) {
+	list.Payload = append(list.Payload, obj)
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+/*******************************************************************************
+ * Digests are returned as arrays of numbers.
+ */
+func bytesToInts(bytes []byte) []int {
+	var ints = make([]int, len(bytes))
+	for i, b := range bytes { ints[i] = int(b) }
+	return ints
+}
+
+type sessionToken struct {
+	baseDesc
+	UniqueSessionId string
+	AuthenticatedUserid string
+	RealmId string
+	IsAdmin bool
+}
+
+type realmDesc struct {
+	baseDesc
+	Id string
+	Name string
+	OrgFullName string
+	AdminUserId string
+	Description string
+	CreationDate string
+}
+
+func (store *store) newRealmDesc(r *realm) *realmDesc {
+	return &realmDesc{
+		baseDesc: newBaseDesc("RealmDesc"),
+		Id: r.id,
+		Name: r.name,
+		OrgFullName: r.orgFullName,
+		AdminUserId: r.adminUserId,
+		Description: r.description,
+		CreationDate: formatTime(r.creationDate),
+	}
+}
+
+type userDesc struct {
+	baseDesc
+	Id string
+	UserId string
+	Name string
+	EmailAddress string
+	RealmId string
+	Enabled bool
+	DefaultRepoId string
+	CanModifyTheseRealms []string
+}
+
+func (store *store) newUserDesc(u *user) *userDesc {
+	return &userDesc{
+		baseDesc: newBaseDesc("UserDesc"),
+		Id: u.id,
+		UserId: u.userId,
+		Name: u.name,
+		EmailAddress: u.emailAddress,
+		RealmId: u.realmId,
+		Enabled: u.enabled,
+		DefaultRepoId: u.defaultRepoId,
+		CanModifyTheseRealms: store.getRealmsUserCanModify(u),
+	}
+}
+
+type groupDesc struct {
+	baseDesc
+	Id string
+	RealmId string
+	Name string
+	Description string
+	CreationDate string
+}
+
+func (store *store) newGroupDesc(g *group) *groupDesc {
+	return &groupDesc{
+		baseDesc: newBaseDesc("GroupDesc"),
+		Id: g.id,
+		RealmId: g.realmId,
+		Name: g.name,
+		Description: g.description,
+		CreationDate: formatTime(g.creationDate),
+	}
+}
+
+type repoDesc struct {
+	baseDesc
+	Id string
+	RealmId string
+	Name string
+	Description string
+	CreationDate string
+	DockerfileIds []string
+}
+
+func (store *store) newRepoDesc(r *repo) *repoDesc {
+	var desc = &repoDesc{
+		baseDesc: newBaseDesc("RepoDesc"),
+		Id: r.id,
+		RealmId: r.realmId,
+		Name: r.name,
+		Description: r.description,
+		CreationDate: formatTime(r.creationDate),
+		DockerfileIds: make([]string, 0),
+	}
+	store.forEach(func(obj interface{}) {
+		if d, isType := obj.(*dockerfile); isType && (d.repoId == r.id) {
+			desc.DockerfileIds = append(desc.DockerfileIds, d.id)
+		}
+	})
+	return desc
+}
+
+type dockerfileDesc struct {
+	baseDesc
+	Id string
+	RepoId string
+	Name string
+	Description string
+	CreationDate string
+	ParameterValueDescs []parameterValue
+}
+
+func (store *store) newDockerfileDesc(d *dockerfile) *dockerfileDesc {
+	return &dockerfileDesc{
+		baseDesc: newBaseDesc("DockerfileDesc"),
+		Id: d.id,
+		RepoId: d.repoId,
+		Name: d.name,
+		Description: d.description,
+		CreationDate: formatTime(d.creationDate),
+		ParameterValueDescs: getDockerfileParams(d.content),
+	}
+}
+
+/*******************************************************************************
+ * Return the build parameters that a dockerfile declares, via ARG lines, with
+ * their default values, if any, as written (including any quotes).
+ */
+func getDockerfileParams(content []byte) []parameterValue {
+	var params = make([]parameterValue, 0)
+	for _, line := range strings.Split(string(content), "\n") {
+		var fields = strings.Fields(line)
+		if (len(fields) < 2) || (strings.ToUpper(fields[0]) != "ARG") { continue }
+		var decl = strings.TrimSpace(strings.TrimSpace(line)[len(fields[0]):])
+		var parts = strings.SplitN(decl, "=", 2)
+		var param = parameterValue{ Name: parts[0] }
+		if len(parts) == 2 { param.Value = parts[1] }
+		params = append(params, param)
+	}
+	return params
+}
+
+type dockerImageDesc struct {
+	baseDesc
+	ObjId string
+	RepoId string
+	Name string
+	Description string
+	CreationDate string
+	ScanConfigIds []string
+}
+
+func (store *store) newDockerImageDesc(i *dockerImage) *dockerImageDesc {
+	return &dockerImageDesc{
+		baseDesc: newBaseDesc("DockerImageDesc"),
+		ObjId: i.id,
+		RepoId: i.repoId,
+		Name: i.name,
+		Description: i.description,
+		CreationDate: formatTime(i.creationDate),
+		ScanConfigIds: append(make([]string, 0), i.scanConfigIds...),
+	}
+}
+
+type dockerImageVersionDesc struct {
+	baseDesc
+	ObjId string
+	Version string
+	ImageObjId string
+	ImageName string
+	RepoId string
+	ImageCreationEventId string
+	CreationDate string
+	Digest []int
+	Signature []int
+	ScanEventIds []string
+	DockerBuildOutput string
+}
+
+func (store *store) newDockerImageVersionDesc(v *dockerImageVersion) *dockerImageVersionDesc {
+	var image = store.getDockerImage(v.imageId)
+	var digest = sha256.Sum256(v.content)
+	var signature = sha512.Sum512(v.content)
+	var desc = &dockerImageVersionDesc{
+		baseDesc: newBaseDesc("DockerImageVersionDesc"),
+		ObjId: v.id,
+		Version: v.version,
+		ImageObjId: v.imageId,
+		ImageName: image.name,
+		RepoId: image.repoId,
+		ImageCreationEventId: v.creationEventId,
+		CreationDate: formatTime(v.creationDate),
+		Digest: bytesToInts(digest[:]),
+		Signature: bytesToInts(signature[:]),
+		ScanEventIds: make([]string, 0),
+		DockerBuildOutput: v.buildOutput,
+	}
+	store.forEach(func(obj interface{}) {
+		if e, isType := obj.(*scanEvent); isType && (e.imageVersionId == v.id) {
+			desc.ScanEventIds = append(desc.ScanEventIds, e.id)
+		}
+	})
+	return desc
+}
+
+type scanConfigDesc struct {
+	baseDesc
+	Id string
+	RepoId string
+	Name string
+	Description string
+	ProviderName string
+	SuccessExpression string
+	FlagId string
+	ScanParameterValueDescs []parameterValue
+	DockerImagesIdsThatUse []string
+}
+
+func (store *store) newScanConfigDesc(s *scanConfig) *scanConfigDesc {
+	var desc = &scanConfigDesc{
+		baseDesc: newBaseDesc("ScanConfigDesc"),
+		Id: s.id,
+		RepoId: s.repoId,
+		Name: s.name,
+		Description: s.description,
+		ProviderName: s.providerName,
+		SuccessExpression: s.successExpression,
+		FlagId: s.flagId,
+		ScanParameterValueDescs: append(make([]parameterValue, 0), s.parameterValues...),
+		DockerImagesIdsThatUse: make([]string, 0),
+	}
+	store.forEach(func(obj interface{}) {
+		if i, isType := obj.(*dockerImage); isType {
+			for _, scanConfigId := range i.scanConfigIds {
+				if scanConfigId == s.id {
+					desc.DockerImagesIdsThatUse = append(desc.DockerImagesIdsThatUse, i.id)
+				}
+			}
+		}
+	})
+	return desc
+}
+
+type flagDesc struct {
+	baseDesc
+	FlagId string
+	RepoId string
+	Name string
+	Description string
+	ImageURL string
+}
+
+func (store *store) newFlagDesc(f *flag) *flagDesc {
+	return &flagDesc{
+		baseDesc: newBaseDesc("FlagDesc"),
+		FlagId: f.id,
+		RepoId: f.repoId,
+		Name: f.name,
+		Description: f.description,
+		ImageURL: "getFlagImage?FlagId=" + f.id,
+	}
+}
+
+type permissionDesc struct {
+	baseDesc
+	ACLEntryId string
+	PartyId string
+	ResourceId string
+	CanCreateIn bool
+	CanRead bool
+	CanWrite bool
+	CanExecute bool
+	CanDelete bool
+}
+
+/*******************************************************************************
+ * entry may be nil, in which case the party has no permissions.
+ */
+func newPermissionDesc(partyId, resourceId string, entry *aclEntry) *permissionDesc {
+	var desc = &permissionDesc{
+		baseDesc: newBaseDesc("PermissionDesc"),
+		PartyId: partyId,
+		ResourceId: resourceId,
+	}
+	if entry != nil {
+		desc.ACLEntryId = entry.id
+		desc.CanCreateIn = entry.mask[CanCreateIn]
+		desc.CanRead = entry.mask[CanRead]
+		desc.CanWrite = entry.mask[CanWrite]
+		desc.CanExecute = entry.mask[CanExecute]
+		desc.CanDelete = entry.mask[CanDelete]
+	}
+	return desc
+}
+
+type dockerfileExecEventDesc struct {
+	baseDesc
+	Id string
+	When string
+	UserObjId string
+	DockerfileId string
+	ImageVersionObjId string
+	ParameterValues []parameterValue
+	DockerfileContent string
+}
+
+func (store *store) newDockerfileExecEventDesc(e *dockerfileExecEvent) *dockerfileExecEventDesc {
+	return &dockerfileExecEventDesc{
+		baseDesc: newBaseDesc("DockerfileExecEventDesc"),
+		Id: e.id,
+		When: formatTime(e.when),
+		UserObjId: e.userObjId,
+		DockerfileId: e.dockerfileId,
+		ImageVersionObjId: e.imageVersionId,
+		ParameterValues: append(make([]parameterValue, 0), e.parameterValues...),
+		DockerfileContent: e.dockerfileContent,
+	}
+}
+
+type vulnerabilityDesc struct {
+	VCE_ID string
+	Description string
+}
+
+type scanEventDesc struct {
+	baseDesc
+	Id string
+	When string
+	UserObjId string
+	ScanConfigId string
+	ProviderName string
+	ParameterValueDescs []parameterValue
+	ImageVersionObjId string
+	Score string
+	VulnerabilityDescs []vulnerabilityDesc
+}
+
+func (store *store) newScanEventDesc(e *scanEvent) *scanEventDesc {
+	return &scanEventDesc{
+		baseDesc: newBaseDesc("ScanEventDesc"),
+		Id: e.id,
+		When: formatTime(e.when),
+		UserObjId: e.userObjId,
+		ScanConfigId: e.scanConfigId,
+		ProviderName: e.providerName,
+		ParameterValueDescs: append(make([]parameterValue, 0), e.parameterValues...),
+		ImageVersionObjId: e.imageVersionId,
+		Score: e.score,
+		VulnerabilityDescs: make([]vulnerabilityDesc, 0),
+	}
+}
+
+/*******************************************************************************
+ * Return a desc for an event of either kind.
+ */
+func (store *store) newEventDesc(obj interface{}) interface{} {
+	switch e := obj.(type) {
+	case *dockerfileExecEvent: return store.newDockerfileExecEventDesc(e)
+	case *scanEvent: return store.newScanEventDesc(e)
+	default: return nil
+	}
+}
+
+/*******************************************************************************
+ * The scan status of an image: the most recent scan of any of its versions.
+ */
+type imageStatusDesc struct {
+	baseDesc
+	EventId string
+	When string
+	UserObjId string
+	ScanConfigId string
+	ProviderName string
+	ParameterValueDescs []parameterValue
+	Score string
+}
+
+type scanProviderDesc struct {
+	baseDesc
+	Name string
+	Description string
+	Parameters []scanParameterDesc
+}
+
+type scanParameterDesc struct {
+	Name string
+	Description string
+}
@@ -0,0 +1,1775 @@
+/*******************************************************************************
+ * The REST requests that the fake server implements. Each handler is called
+ * with the server locked. Request and response field names are those that the
+ * Try* methods of helpers.TestContext use.
+ */
+
+package fakeserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (server *Server) registerHandlers() {
+	var handlers = map[string]handlerFunc{
+		"ping": ping,
+		"clearAll": clearAll,
+		"createRealmAnon": createRealmAnon,
+		"authenticate": authenticate,
+		"logout": logout,
+		"createRealm": createRealm,
+		"getRealmDesc": getRealmDesc,
+		"getRealmByName": getRealmByName,
+		"getAllRealms": getAllRealms,
+		"getRealmUsers": getRealmUsers,
+		"getRealmGroups": getRealmGroups,
+		"getRealmRepos": getRealmRepos,
+		"remRealmUser": remRealmUser,
+		"deactivateRealm": deactivateRealm,
+		"createUser": createUser,
+		"getUserDesc": getUserDesc,
+		"getMyDesc": getMyDesc,
+		"disableUser": disableUser,
+		"reenableUser": reenableUser,
+		"updateUserInfo": updateUserInfo,
+		"userExists": userExists,
+		"changePassword": changePassword,
+		"moveUserToRealm": moveUserToRealm,
+		"createGroup": createGroup,
+		"getGroupDesc": getGroupDesc,
+		"getGroupUsers": getGroupUsers,
+		"addGroupUser": addGroupUser,
+		"remGroupUser": remGroupUser,
+		"deleteGroup": deleteGroup,
+		"getMyGroups": getMyGroups,
+		"getMyRealms": getMyRealms,
+		"getMyRepos": getMyRepos,
+		"getMyDockerfiles": getMyDockerfiles,
+		"getMyDockerImages": getMyDockerImages,
+		"getMyScanConfigs": getMyScanConfigs,
+		"getMyFlags": getMyFlags,
+		"createRepo": createRepo,
+		"getRepoDesc": getRepoDesc,
+		"deleteRepo": deleteRepo,
+		"addDockerfile": addDockerfile,
+		"getDockerfiles": getDockerfiles,
+		"getDockerfileDesc": getDockerfileDesc,
+		"replaceDockerfile": replaceDockerfile,
+		"remDockerfile": remDockerfile,
+		"execDockerfile": execDockerfile,
+		"addAndExecDockerfile": addAndExecDockerfile,
+		"getDockerImages": getDockerImages,
+		"getDockerImageDesc": getDockerImageDesc,
+		"getDockerImageVersions": getDockerImageVersions,
+		"remDockerImage": remDockerImage,
+		"remImageVersion": remImageVersion,
+		"downloadImage": downloadImage,
+		"getDockerImageStatus": getDockerImageStatus,
+		"getEventDesc": getEventDesc,
+		"getUserEvents": getUserEvents,
+		"getDockerImageEvents": getDockerImageEvents,
+		"getDockerfileEvents": getDockerfileEvents,
+		"setPermission": setPermission,
+		"addPermission": addPermission,
+		"getPermission": getPermission,
+		"remPermission": remPermission,
+		"getScanProviders": getScanProviders,
+		"defineScanConfig": defineScanConfig,
+		"updateScanConfig": updateScanConfig,
+		"getScanConfigDesc": getScanConfigDesc,
+		"getScanConfigDescByName": getScanConfigDescByName,
+		"remScanConfig": remScanConfig,
+		"useScanConfigForImage": useScanConfigForImage,
+		"stopUsingScanConfigForImage": stopUsingScanConfigForImage,
+		"scanImage": scanImage,
+		"defineFlag": defineFlag,
+		"getFlagDesc": getFlagDesc,
+		"getFlagDescByName": getFlagDescByName,
+		"getFlagImage": getFlagImage,
+		"remFlag": remFlag,
+		"enableEmailVerification": enableEmailVerification,
+		"validateAccountVerificationToken": validateAccountVerificationToken,
+	}
+	for name, handler := range handlers { server.handlers[name] = handler }
+}
+
+/*******************************************************************************
+ * The scan providers that the fake server claims to have, and their parameters.
+ */
+var scanProviders = []scanProviderDesc{
+	{
+		baseDesc: newBaseDesc("ScanProviderDesc"),
+		Name: "clair",
+		Description: "CoreOS Clair",
+		Parameters: []scanParameterDesc{},
+	},
+	{
+		baseDesc: newBaseDesc("ScanProviderDesc"),
+		Name: "twistlock",
+		Description: "Twistlock",
+		Parameters: []scanParameterDesc{
+			{ Name: "UserId", Description: "Twistlock user id" },
+			{ Name: "Password", Description: "Twistlock password" },
+		},
+	},
+}
+
+/*******************************************************************************
+ * Form fields of defineScanConfig and updateScanConfig that are not scan
+ * provider parameters.
+ */
+var scanConfigFieldNames = []string{"Log", "SessionId", "ScanConfigId", "Name",
+	"Description", "RepoId", "ProviderName", "SuccessExpression"}
+
+var permissionNames = []string{"CanCreateIn", "CanRead", "CanWrite", "CanExecute", "CanDelete"}
+
+// -----------------------------------------------------------------------------
+// Utilities used by the handlers.
+
+/*******************************************************************************
+ * Return the authenticated user, or an error if there is none.
+ */
+func requireUser(req *request) (*user, error) {
+	if req.user == nil { return nil, fail(http.StatusUnauthorized, "Not authenticated") }
+	return req.user, nil
+}
+
+/*******************************************************************************
+ * Return an error if the user does not have the permission on the resource.
+ */
+func (server *Server) requirePermission(u *user, resourceId string, permission int) error {
+	if server.store.hasPermission(u, resourceId, permission) { return nil }
+	return fail(http.StatusForbidden, "User %s does not have %s permission for %s",
+		u.userId, permissionNames[permission], resourceId)
+}
+
+/*******************************************************************************
+ * Return the value of each of the named fields, or an error if any is empty.
+ */
+func requireValues(req *request, names ...string) ([]string, error) {
+	var values = make([]string, len(names))
+	for i, name := range names {
+		values[i] = req.get(name)
+		if values[i] == "" { return nil, fail(http.StatusBadRequest, "%s is required", name) }
+	}
+	return values, nil
+}
+
+/*******************************************************************************
+ * Return an error if no file was posted.
+ */
+func requireFile(req *request) error {
+	if req.fileContent == nil { return fail(http.StatusBadRequest, "No file was posted") }
+	return nil
+}
+
+func notFound(kind, id string) error {
+	return fail(http.StatusNotFound, "%s %s not found", kind, id)
+}
+
+func (store *store) findRealm(id string) (*realm, error) {
+	var r = store.getRealm(id)
+	if (r == nil) || (! r.active) { return nil, notFound("Realm", id) }
+	return r, nil
+}
+
+func (store *store) findUser(id string) (*user, error) {
+	var u = store.getUser(id)
+	if u == nil { return nil, notFound("User", id) }
+	return u, nil
+}
+
+func (store *store) findGroup(id string) (*group, error) {
+	var g = store.getGroup(id)
+	if g == nil { return nil, notFound("Group", id) }
+	return g, nil
+}
+
+func (store *store) findRepo(id string) (*repo, error) {
+	var r = store.getRepo(id)
+	if r == nil { return nil, notFound("Repo", id) }
+	return r, nil
+}
+
+func (store *store) findDockerfile(id string) (*dockerfile, error) {
+	var d = store.getDockerfile(id)
+	if d == nil { return nil, notFound("Dockerfile", id) }
+	return d, nil
+}
+
+func (store *store) findDockerImage(id string) (*dockerImage, error) {
+	var i = store.getDockerImage(id)
+	if i == nil { return nil, notFound("Docker image", id) }
+	return i, nil
+}
+
+func (store *store) findScanConfig(id string) (*scanConfig, error) {
+	var s = store.getScanConfig(id)
+	if s == nil { return nil, notFound("Scan config", id) }
+	return s, nil
+}
+
+func (store *store) findFlag(id string) (*flag, error) {
+	var f = store.getFlag(id)
+	if f == nil { return nil, notFound("Flag", id) }
+	return f, nil
+}
+
+/*******************************************************************************
+ * Return the image and version that the Id refers to. If the Id is that of an
+ * image, the version is the image's most recent version, or nil if it has none.
+ */
+func (store *store) findImageOrVersion(id string) (*dockerImage, *dockerImageVersion, error) {
+	var version = store.getDockerImageVersion(id)
+	if version != nil { return store.getDockerImage(version.imageId), version, nil }
+	var image = store.getDockerImage(id)
+	if image == nil { return nil, nil, notFound("Docker image", id) }
+	var versions = store.getImageVersions(image.id)
+	if len(versions) == 0 { return image, nil, nil }
+	return image, versions[len(versions)-1], nil
+}
+
+/*******************************************************************************
+ * Return the repo that the RepoId field specifies. If it is empty, return the
+ * user's default repo, creating it if the user does not have one.
+ */
+func (server *Server) getRepoOrDefault(u *user, repoId string) (*repo, error) {
+	var store = server.store
+	if repoId != "" { return store.findRepo(repoId) }
+	var r = store.getRepo(u.defaultRepoId)
+	if r != nil { return r, nil }
+
+	var realm, err = store.findRealm(u.realmId)
+	if err != nil {
+		return nil, fail(http.StatusBadRequest, "No RepoId was given, and user %s has no realm", u.userId)
+	}
+	err = server.requirePermission(u, realm.id, CanCreateIn)
+	if err != nil { return nil, err }
+	r = store.createRepo(u, realm.id, u.userId + "_default", "Default repo for " + u.userId)
+	u.defaultRepoId = r.id
+	return r, nil
+}
+
+/*******************************************************************************
+ * Parse build parameters, in the form name1:value1;name2:value2...
+ */
+func parseParams(paramStr string) ([]parameterValue, error) {
+	var params = make([]parameterValue, 0)
+	if paramStr == "" { return params, nil }
+	for _, param := range strings.Split(paramStr, ";") {
+		var parts = strings.SplitN(param, ":", 2)
+		if len(parts) != 2 { return nil, fail(http.StatusBadRequest, "Ill-formed parameter: %s", param) }
+		params = append(params, parameterValue{ Name: parts[0], Value: parts[1] })
+	}
+	return params, nil
+}
+
+/*******************************************************************************
+ * Return the scan provider parameters: the form fields that are not otherwise
+ * used by defineScanConfig and updateScanConfig, sorted by name.
+ */
+func getProviderParams(req *request) []parameterValue {
+	var names = make([]string, 0)
+	for name, _ := range req.values {
+		var isField = false
+		for _, fieldName := range scanConfigFieldNames {
+			if name == fieldName { isField = true }
+		}
+		if ! isField { names = append(names, name) }
+	}
+	sort.Strings(names)
+	var params = make([]parameterValue, 0)
+	for _, name := range names {
+		params = append(params, parameterValue{ Name: name, Value: req.get(name) })
+	}
+	return params
+}
+
+func isScanProvider(name string) bool {
+	for _, provider := range scanProviders {
+		if provider.Name == name { return true }
+	}
+	return false
+}
+
+/*******************************************************************************
+ * Return a list of the objects, of the type tested by isOfType, on which the
+ * user has a permission of their own.
+ */
+func (server *Server) getMyObjects(u *user, isOfType func(obj interface{}) (string, bool),
+	newDesc func(obj interface{}) interface{}) *listDesc {
+	var list = newListDesc()
+	server.store.forEach(func(obj interface{}) {
+		var id, isType = isOfType(obj)
+		if isType && server.store.hasAnyDirectPermission(u, id) { list.add(newDesc(obj)) }
+	})
+	return list
+}
+
+// -----------------------------------------------------------------------------
+// Object creation and removal.
+
+func (store *store) createRealm(name, orgFullName, desc, adminUserId string) *realm {
+	var r = &realm{
+		id: store.createId("realm"),
+		name: name,
+		orgFullName: orgFullName,
+		description: desc,
+		adminUserId: adminUserId,
+		active: true,
+		creationDate: time.Now(),
+	}
+	store.add(r.id, r)
+	return r
+}
+
+func (store *store) createUser(userId, name, emailAddress, password, realmId string) *user {
+	var u = &user{
+		id: store.createId("user"),
+		userId: userId,
+		name: name,
+		emailAddress: emailAddress,
+		password: password,
+		realmId: realmId,
+		enabled: true,
+		creationDate: time.Now(),
+	}
+	store.add(u.id, u)
+	return u
+}
+
+func (store *store) createRepo(creator *user, realmId, name, desc string) *repo {
+	var r = &repo{
+		id: store.createId("repo"),
+		realmId: realmId,
+		name: name,
+		description: desc,
+		creationDate: time.Now(),
+	}
+	store.add(r.id, r)
+	store.grantAll(creator, r.id)
+	return r
+}
+
+func (store *store) createDockerfile(creator *user, repoId, name, desc string,
+	content []byte) *dockerfile {
+	var d = &dockerfile{
+		id: store.createId("dockerfile"),
+		repoId: repoId,
+		name: name,
+		description: desc,
+		content: content,
+		creationDate: time.Now(),
+	}
+	store.add(d.id, d)
+	store.grantAll(creator, d.id)
+	return d
+}
+
+func (store *store) createFlag(creator *user, repoId, name, desc string, image []byte) *flag {
+	var f = &flag{
+		id: store.createId("flag"),
+		repoId: repoId,
+		name: name,
+		description: desc,
+		image: image,
+		creationDate: time.Now(),
+	}
+	store.add(f.id, f)
+	store.grantAll(creator, f.id)
+	return f
+}
+
+/*******************************************************************************
+ * "Build" an image from a dockerfile: record a new version of the image that
+ * has the specified name in the dockerfile's repo, creating the image if there
+ * is none, and an event for the build.
+ */
+func (store *store) buildImage(u *user, d *dockerfile, imageName string,
+	params []parameterValue) *dockerImageVersion {
+
+	var image *dockerImage
+	store.forEach(func(obj interface{}) {
+		if i, isType := obj.(*dockerImage); isType && (i.repoId == d.repoId) && (i.name == imageName) {
+			image = i
+		}
+	})
+	if image == nil {
+		image = &dockerImage{
+			id: store.createId("image"),
+			repoId: d.repoId,
+			name: imageName,
+			scanConfigIds: make([]string, 0),
+			creationDate: time.Now(),
+		}
+		if image.name == "" { image.name = image.id }
+		store.add(image.id, image)
+		store.grantAll(u, image.id)
+	}
+	image.noOfVersionsCreated++
+
+	var version = &dockerImageVersion{
+		id: store.createId("imagever"),
+		imageId: image.id,
+		version: fmt.Sprintf("%d", image.noOfVersionsCreated),
+		creationDate: time.Now(),
+	}
+	version.content = []byte(fmt.Sprintf("Image %s:%s (%s), built from %s\n%s",
+		image.name, version.version, version.id, d.name, string(d.content)))
+	version.buildOutput = fmt.Sprintf("Successfully built %s:%s", image.name, version.version)
+
+	var event = &dockerfileExecEvent{
+		id: store.createId("event"),
+		when: time.Now(),
+		userObjId: u.id,
+		dockerfileId: d.id,
+		imageId: image.id,
+		imageVersionId: version.id,
+		parameterValues: params,
+		dockerfileContent: string(d.content),
+	}
+	version.creationEventId = event.id
+	store.add(version.id, version)
+	store.add(event.id, event)
+	return version
+}
+
+/*******************************************************************************
+ * Remove a dockerfile. Events that refer to it are retained, but their
+ * reference to it is cleared.
+ */
+func (store *store) removeDockerfile(d *dockerfile) {
+	store.forEach(func(obj interface{}) {
+		if e, isType := obj.(*dockerfileExecEvent); isType && (e.dockerfileId == d.id) {
+			e.dockerfileId = ""
+		}
+	})
+	store.remove(d.id)
+}
+
+/*******************************************************************************
+ * Remove an image version. Events that refer to it are retained, but their
+ * reference to it is cleared.
+ */
+func (store *store) removeImageVersion(v *dockerImageVersion) {
+	store.forEach(func(obj interface{}) {
+		switch e := obj.(type) {
+		case *dockerfileExecEvent: if e.imageVersionId == v.id { e.imageVersionId = "" }
+		case *scanEvent: if e.imageVersionId == v.id { e.imageVersionId = "" }
+		}
+	})
+	store.remove(v.id)
+}
+
+func (store *store) removeImage(i *dockerImage) {
+	for _, v := range store.getImageVersions(i.id) { store.removeImageVersion(v) }
+	store.remove(i.id)
+}
+
+func (store *store) removeScanConfig(s *scanConfig) {
+	store.forEach(func(obj interface{}) {
+		if i, isType := obj.(*dockerImage); isType {
+			var scanConfigIds = make([]string, 0)
+			for _, id := range i.scanConfigIds {
+				if id != s.id { scanConfigIds = append(scanConfigIds, id) }
+			}
+			i.scanConfigIds = scanConfigIds
+		}
+	})
+	store.remove(s.id)
+}
+
+/*******************************************************************************
+ * Remove a repo and everything in it. Users for which it is the default repo no
+ * longer have a default repo.
+ */
+func (store *store) removeRepo(r *repo) {
+	store.forEach(func(obj interface{}) {
+		switch o := obj.(type) {
+		case *dockerfile: if o.repoId == r.id { store.removeDockerfile(o) }
+		case *dockerImage: if o.repoId == r.id { store.removeImage(o) }
+		case *scanConfig: if o.repoId == r.id { store.removeScanConfig(o) }
+		case *flag: if o.repoId == r.id { store.remove(o.id) }
+		case *user: if o.defaultRepoId == r.id { o.defaultRepoId = "" }
+		}
+	})
+	store.remove(r.id)
+}
+
+// -----------------------------------------------------------------------------
+// Handlers.
+
+func ping(server *Server, req *request) (interface{}, error) {
+	return newResult("Server is up"), nil
+}
+
+/*******************************************************************************
+ * Discard all state. As with the SafeHarbor server in debug mode, no session is
+ * required.
+ */
+func clearAll(server *Server, req *request) (interface{}, error) {
+	server.store = newStore()
+	return newResult("All data cleared"), nil
+}
+
+/*******************************************************************************
+ * Create a realm and its admin user, without being logged in.
+ */
+func createRealmAnon(server *Server, req *request) (interface{}, error) {
+	var store = server.store
+	var values, err = requireValues(req, "UserId", "Password", "RealmName")
+	if err != nil { return nil, err }
+	var userId, password, realmName = values[0], values[1], values[2]
+	if store.getRealmByName(realmName) != nil {
+		return nil, fail(http.StatusBadRequest, "A realm named %s already exists", realmName)
+	}
+	if store.getUserByUserId(userId) != nil {
+		return nil, fail(http.StatusBadRequest, "User %s already exists", userId)
+	}
+	var r = store.createRealm(realmName, req.get("OrgFullName"), "", userId)
+	var u = store.createUser(userId, req.get("UserName"), req.get("EmailAddress"), password, r.id)
+	store.grantAll(u, r.id)
+	return store.newUserDesc(u), nil
+}
+
+func authenticate(server *Server, req *request) (interface{}, error) {
+	var store = server.store
+	var u = store.getUserByUserId(req.get("UserId"))
+	if (u == nil) || (u.password != req.get("Password")) {
+		return nil, fail(http.StatusUnauthorized, "Invalid user id or password")
+	}
+	if ! u.enabled { return nil, fail(http.StatusForbidden, "User %s is disabled", u.userId) }
+	var sessionId = store.createId("session")
+	store.sessions[sessionId] = u.id
+	return &sessionToken{
+		baseDesc: newBaseDesc("SessionToken"),
+		UniqueSessionId: sessionId,
+		AuthenticatedUserid: u.userId,
+		RealmId: u.realmId,
+		IsAdmin: len(store.getRealmsUserCanModify(u)) > 0,
+	}, nil
+}
+
+func logout(server *Server, req *request) (interface{}, error) {
+	var _, err = requireUser(req)
+	if err != nil { return nil, err }
+	delete(server.store.sessions, req.sessionId)
+	return newResult("Logged out"), nil
+}
+
+func createRealm(server *Server, req *request) (interface{}, error) {
+	var store = server.store
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var values []string
+	values, err = requireValues(req, "RealmName")
+	if err != nil { return nil, err }
+	if store.getRealmByName(values[0]) != nil {
+		return nil, fail(http.StatusBadRequest, "A realm named %s already exists", values[0])
+	}
+	var r = store.createRealm(values[0], req.get("OrgFullName"), req.get("Description"), u.userId)
+	store.grantAll(u, r.id)
+	return store.newRealmDesc(r), nil
+}
+
+func getRealmDesc(server *Server, req *request) (interface{}, error) {
+	var _, err = requireUser(req)
+	if err != nil { return nil, err }
+	var r *realm
+	r, err = server.store.findRealm(req.get("RealmId"))
+	if err != nil { return nil, err }
+	return server.store.newRealmDesc(r), nil
+}
+
+func getRealmByName(server *Server, req *request) (interface{}, error) {
+	var _, err = requireUser(req)
+	if err != nil { return nil, err }
+	var r = server.store.getRealmByName(req.get("RealmName"))
+	if (r == nil) || (! r.active) { return nil, notFound("Realm", req.get("RealmName")) }
+	return server.store.newRealmDesc(r), nil
+}
+
+func getAllRealms(server *Server, req *request) (interface{}, error) {
+	var _, err = requireUser(req)
+	if err != nil { return nil, err }
+	var list = newListDesc()
+	server.store.forEach(func(obj interface{}) {
+		if r, isType := obj.(*realm); isType && r.active { list.add(server.store.newRealmDesc(r)) }
+	})
+	return list, nil
+}
+
+func getRealmUsers(server *Server, req *request) (interface{}, error) {
+	var _, err = requireUser(req)
+	if err != nil { return nil, err }
+	var r *realm
+	r, err = server.store.findRealm(req.get("RealmId"))
+	if err != nil { return nil, err }
+	var list = newListDesc()
+	server.store.forEach(func(obj interface{}) {
+		if u, isType := obj.(*user); isType && (u.realmId == r.id) { list.add(server.store.newUserDesc(u)) }
+	})
+	return list, nil
+}
+
+func getRealmGroups(server *Server, req *request) (interface{}, error) {
+	var _, err = requireUser(req)
+	if err != nil { return nil, err }
+	var r *realm
+	r, err = server.store.findRealm(req.get("RealmId"))
+	if err != nil { return nil, err }
+	var list = newListDesc()
+	server.store.forEach(func(obj interface{}) {
+		if g, isType := obj.(*group); isType && (g.realmId == r.id) { list.add(server.store.newGroupDesc(g)) }
+	})
+	return list, nil
+}
+
+func getRealmRepos(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var r *realm
+	r, err = server.store.findRealm(req.get("RealmId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, r.id, CanRead)
+	if err != nil { return nil, err }
+	var list = newListDesc()
+	server.store.forEach(func(obj interface{}) {
+		if repo, isType := obj.(*repo); isType && (repo.realmId == r.id) {
+			list.add(server.store.newRepoDesc(repo))
+		}
+	})
+	return list, nil
+}
+
+func remRealmUser(server *Server, req *request) (interface{}, error) {
+	var store = server.store
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var r *realm
+	r, err = store.findRealm(req.get("RealmId"))
+	if err != nil { return nil, err }
+	var member *user
+	member, err = store.findUser(req.get("UserObjId"))
+	if err != nil { return nil, err }
+	if member.realmId != r.id {
+		return nil, fail(http.StatusBadRequest, "User %s is not in realm %s", member.userId, r.name)
+	}
+	err = server.requirePermission(u, r.id, CanWrite)
+	if err != nil { return nil, err }
+	member.realmId = ""
+	return newResult("User removed from realm"), nil
+}
+
+func deactivateRealm(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var r *realm
+	r, err = server.store.findRealm(req.get("RealmId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, r.id, CanDelete)
+	if err != nil { return nil, err }
+	r.active = false
+	return newResult("Realm deactivated"), nil
+}
+
+func createUser(server *Server, req *request) (interface{}, error) {
+	var store = server.store
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var values []string
+	values, err = requireValues(req, "UserId", "Password", "RealmId")
+	if err != nil { return nil, err }
+	var r *realm
+	r, err = store.findRealm(values[2])
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, r.id, CanWrite)
+	if err != nil { return nil, err }
+	if store.getUserByUserId(values[0]) != nil {
+		return nil, fail(http.StatusBadRequest, "User %s already exists", values[0])
+	}
+	var newUser = store.createUser(values[0], req.get("UserName"), req.get("EmailAddress"),
+		values[1], r.id)
+	if store.emailVerificationEnabled {
+		// There is no email service: make the token available another way.
+		var token = store.createId("token")
+		store.verificationTokens[token] = newUser.id
+		fmt.Println(fmt.Sprintf("fakeserver: account verification token for %s: %s",
+			newUser.userId, token))
+	}
+	return store.newUserDesc(newUser), nil
+}
+
+/*******************************************************************************
+ * Users can always obtain their own desc; otherwise, read permission on the
+ * user's realm is required.
+ */
+func getUserDesc(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var target = server.store.getUserByUserId(req.get("UserId"))
+	if target == nil { return nil, notFound("User", req.get("UserId")) }
+	if target != u {
+		err = server.requirePermission(u, target.id, CanRead)
+		if err != nil { return nil, err }
+	}
+	return server.store.newUserDesc(target), nil
+}
+
+func getMyDesc(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	return server.store.newUserDesc(u), nil
+}
+
+/*******************************************************************************
+ * A disabled user cannot authenticate, and the user's sessions end.
+ */
+func disableUser(server *Server, req *request) (interface{}, error) {
+	var store = server.store
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var target *user
+	target, err = store.findUser(req.get("UserObjId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, target.id, CanWrite)
+	if err != nil { return nil, err }
+	target.enabled = false
+	for sessionId, userObjId := range store.sessions {
+		if userObjId == target.id { delete(store.sessions, sessionId) }
+	}
+	return newResult("User disabled"), nil
+}
+
+func reenableUser(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var target *user
+	target, err = server.store.findUser(req.get("UserObjId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, target.id, CanWrite)
+	if err != nil { return nil, err }
+	target.enabled = true
+	return newResult("User reenabled"), nil
+}
+
+/*******************************************************************************
+ * Only users themselves may update their info. Empty fields are not changed.
+ */
+func updateUserInfo(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var target = server.store.getUserByUserId(req.get("UserId"))
+	if target == nil { return nil, notFound("User", req.get("UserId")) }
+	if target != u {
+		return nil, fail(http.StatusForbidden, "User %s may not update the info of user %s",
+			u.userId, target.userId)
+	}
+	if req.get("UserName") != "" { target.name = req.get("UserName") }
+	if req.get("EmailAddress") != "" { target.emailAddress = req.get("EmailAddress") }
+	return server.store.newUserDesc(target), nil
+}
+
+func userExists(server *Server, req *request) (interface{}, error) {
+	var _, err = requireUser(req)
+	if err != nil { return nil, err }
+	if server.store.getUserByUserId(req.get("UserId")) == nil {
+		return nil, notFound("User", req.get("UserId"))
+	}
+	return newResult("User exists"), nil
+}
+
+func changePassword(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	if req.get("UserId") != u.userId {
+		return nil, fail(http.StatusForbidden, "Users may only change their own password")
+	}
+	if req.get("OldPassword") != u.password {
+		return nil, fail(http.StatusForbidden, "Incorrect password")
+	}
+	var values []string
+	values, err = requireValues(req, "NewPassword")
+	if err != nil { return nil, err }
+	u.password = values[0]
+	return newResult("Password changed"), nil
+}
+
+func moveUserToRealm(server *Server, req *request) (interface{}, error) {
+	var store = server.store
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var target *user
+	target, err = store.findUser(req.get("UserObjId"))
+	if err != nil { return nil, err }
+	var r *realm
+	r, err = store.findRealm(req.get("RealmId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, target.id, CanWrite)
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, r.id, CanWrite)
+	if err != nil { return nil, err }
+	target.realmId = r.id
+	return newResult("User moved"), nil
+}
+
+func createGroup(server *Server, req *request) (interface{}, error) {
+	var store = server.store
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var values []string
+	values, err = requireValues(req, "RealmId", "Name")
+	if err != nil { return nil, err }
+	var r *realm
+	r, err = store.findRealm(values[0])
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, r.id, CanWrite)
+	if err != nil { return nil, err }
+	var g = &group{
+		id: store.createId("group"),
+		realmId: r.id,
+		name: values[1],
+		description: req.get("Description"),
+		userObjIds: make([]string, 0),
+		creationDate: time.Now(),
+	}
+	if req.get("AddMe") == "true" { g.userObjIds = append(g.userObjIds, u.id) }
+	store.add(g.id, g)
+	store.grantAll(u, g.id)
+	return store.newGroupDesc(g), nil
+}
+
+func getGroupDesc(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var g *group
+	g, err = server.store.findGroup(req.get("GroupId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, g.id, CanRead)
+	if err != nil { return nil, err }
+	return server.store.newGroupDesc(g), nil
+}
+
+func getGroupUsers(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var g *group
+	g, err = server.store.findGroup(req.get("GroupId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, g.id, CanRead)
+	if err != nil { return nil, err }
+	var list = newListDesc()
+	for _, userObjId := range g.userObjIds {
+		var member = server.store.getUser(userObjId)
+		if member != nil { list.add(server.store.newUserDesc(member)) }
+	}
+	return list, nil
+}
+
+func addGroupUser(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var g *group
+	g, err = server.store.findGroup(req.get("GroupId"))
+	if err != nil { return nil, err }
+	var member *user
+	member, err = server.store.findUser(req.get("UserObjId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, g.id, CanWrite)
+	if err != nil { return nil, err }
+	for _, userObjId := range g.userObjIds {
+		if userObjId == member.id { return newResult("User is already in the group"), nil }
+	}
+	g.userObjIds = append(g.userObjIds, member.id)
+	return newResult("User added to group"), nil
+}
+
+func remGroupUser(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var g *group
+	g, err = server.store.findGroup(req.get("GroupId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, g.id, CanWrite)
+	if err != nil { return nil, err }
+	var userObjIds = make([]string, 0)
+	for _, userObjId := range g.userObjIds {
+		if userObjId != req.get("UserObjId") { userObjIds = append(userObjIds, userObjId) }
+	}
+	if len(userObjIds) == len(g.userObjIds) {
+		return nil, fail(http.StatusBadRequest, "User %s is not in group %s",
+			req.get("UserObjId"), g.name)
+	}
+	g.userObjIds = userObjIds
+	return newResult("User removed from group"), nil
+}
+
+func deleteGroup(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var g *group
+	g, err = server.store.findGroup(req.get("GroupId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, g.id, CanDelete)
+	if err != nil { return nil, err }
+	server.store.remove(g.id)
+	return newResult("Group deleted"), nil
+}
+
+func getMyGroups(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var list = newListDesc()
+	server.store.forEach(func(obj interface{}) {
+		if g, isType := obj.(*group); isType {
+			for _, userObjId := range g.userObjIds {
+				if userObjId == u.id { list.add(server.store.newGroupDesc(g)) }
+			}
+		}
+	})
+	return list, nil
+}
+
+func getMyRealms(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	return server.getMyObjects(u,
+		func(obj interface{}) (string, bool) {
+			var r, isType = obj.(*realm)
+			if (! isType) || (! r.active) { return "", false }
+			return r.id, true
+		},
+		func(obj interface{}) interface{} { return server.store.newRealmDesc(obj.(*realm)) }), nil
+}
+
+func getMyRepos(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	return server.getMyObjects(u,
+		func(obj interface{}) (string, bool) {
+			var r, isType = obj.(*repo)
+			if ! isType { return "", false }
+			return r.id, true
+		},
+		func(obj interface{}) interface{} { return server.store.newRepoDesc(obj.(*repo)) }), nil
+}
+
+func getMyDockerfiles(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	return server.getMyObjects(u,
+		func(obj interface{}) (string, bool) {
+			var d, isType = obj.(*dockerfile)
+			if ! isType { return "", false }
+			return d.id, true
+		},
+		func(obj interface{}) interface{} { return server.store.newDockerfileDesc(obj.(*dockerfile)) }), nil
+}
+
+func getMyDockerImages(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	return server.getMyObjects(u,
+		func(obj interface{}) (string, bool) {
+			var i, isType = obj.(*dockerImage)
+			if ! isType { return "", false }
+			return i.id, true
+		},
+		func(obj interface{}) interface{} { return server.store.newDockerImageDesc(obj.(*dockerImage)) }), nil
+}
+
+func getMyScanConfigs(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	return server.getMyObjects(u,
+		func(obj interface{}) (string, bool) {
+			var s, isType = obj.(*scanConfig)
+			if ! isType { return "", false }
+			return s.id, true
+		},
+		func(obj interface{}) interface{} { return server.store.newScanConfigDesc(obj.(*scanConfig)) }), nil
+}
+
+func getMyFlags(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	return server.getMyObjects(u,
+		func(obj interface{}) (string, bool) {
+			var f, isType = obj.(*flag)
+			if ! isType { return "", false }
+			return f.id, true
+		},
+		func(obj interface{}) interface{} { return server.store.newFlagDesc(obj.(*flag)) }), nil
+}
+
+/*******************************************************************************
+ * If a file is posted, it becomes the repo's first dockerfile.
+ */
+func createRepo(server *Server, req *request) (interface{}, error) {
+	var store = server.store
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var values []string
+	values, err = requireValues(req, "RealmId", "Name")
+	if err != nil { return nil, err }
+	var r *realm
+	r, err = store.findRealm(values[0])
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, r.id, CanCreateIn)
+	if err != nil { return nil, err }
+	var duplicate = false
+	store.forEach(func(obj interface{}) {
+		if repo, isType := obj.(*repo); isType && (repo.realmId == r.id) && (repo.name == values[1]) {
+			duplicate = true
+		}
+	})
+	if duplicate {
+		return nil, fail(http.StatusBadRequest, "A repo named %s already exists in realm %s",
+			values[1], r.name)
+	}
+	var newRepo = store.createRepo(u, r.id, values[1], req.get("Description"))
+	if req.fileContent != nil {
+		store.createDockerfile(u, newRepo.id, req.fileName, "", req.fileContent)
+	}
+	return store.newRepoDesc(newRepo), nil
+}
+
+func getRepoDesc(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var r *repo
+	r, err = server.store.findRepo(req.get("RepoId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, r.id, CanRead)
+	if err != nil { return nil, err }
+	return server.store.newRepoDesc(r), nil
+}
+
+func deleteRepo(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var r *repo
+	r, err = server.store.findRepo(req.get("RepoId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, r.id, CanDelete)
+	if err != nil { return nil, err }
+	server.store.removeRepo(r)
+	return newResult("Repo deleted"), nil
+}
+
+func addDockerfile(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var r *repo
+	r, err = server.getRepoOrDefault(u, req.get("RepoId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, r.id, CanCreateIn)
+	if err != nil { return nil, err }
+	err = requireFile(req)
+	if err != nil { return nil, err }
+	var d = server.store.createDockerfile(u, r.id, req.fileName, req.get("Description"),
+		req.fileContent)
+	return server.store.newDockerfileDesc(d), nil
+}
+
+func getDockerfiles(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var r *repo
+	r, err = server.store.findRepo(req.get("RepoId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, r.id, CanRead)
+	if err != nil { return nil, err }
+	var list = newListDesc()
+	server.store.forEach(func(obj interface{}) {
+		if d, isType := obj.(*dockerfile); isType && (d.repoId == r.id) {
+			list.add(server.store.newDockerfileDesc(d))
+		}
+	})
+	return list, nil
+}
+
+func getDockerfileDesc(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var d *dockerfile
+	d, err = server.store.findDockerfile(req.get("DockerfileId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, d.id, CanRead)
+	if err != nil { return nil, err }
+	return server.store.newDockerfileDesc(d), nil
+}
+
+func replaceDockerfile(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var d *dockerfile
+	d, err = server.store.findDockerfile(req.get("DockerfileId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, d.id, CanWrite)
+	if err != nil { return nil, err }
+	err = requireFile(req)
+	if err != nil { return nil, err }
+	d.content = req.fileContent
+	if req.get("Description") != "" { d.description = req.get("Description") }
+	return newResult("Dockerfile replaced"), nil
+}
+
+func remDockerfile(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var d *dockerfile
+	d, err = server.store.findDockerfile(req.get("DockerfileId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, d.id, CanDelete)
+	if err != nil { return nil, err }
+	server.store.removeDockerfile(d)
+	return newResult("Dockerfile removed"), nil
+}
+
+func execDockerfile(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var d *dockerfile
+	d, err = server.store.findDockerfile(req.get("DockerfileId"))
+	if err != nil { return nil, err }
+	if (req.get("RepoId") != "") && (req.get("RepoId") != d.repoId) {
+		return nil, fail(http.StatusBadRequest, "Dockerfile %s is not in repo %s",
+			d.id, req.get("RepoId"))
+	}
+	err = server.requirePermission(u, d.id, CanExecute)
+	if err != nil { return nil, err }
+	var params []parameterValue
+	params, err = parseParams(req.get("Params"))
+	if err != nil { return nil, err }
+	var version = server.store.buildImage(u, d, req.get("ImageName"), params)
+	return server.store.newDockerImageVersionDesc(version), nil
+}
+
+func addAndExecDockerfile(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var r *repo
+	r, err = server.getRepoOrDefault(u, req.get("RepoId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, r.id, CanCreateIn)
+	if err != nil { return nil, err }
+	err = requireFile(req)
+	if err != nil { return nil, err }
+	var params []parameterValue
+	params, err = parseParams(req.get("Params"))
+	if err != nil { return nil, err }
+	var d = server.store.createDockerfile(u, r.id, req.fileName, req.get("Description"),
+		req.fileContent)
+	var version = server.store.buildImage(u, d, req.get("ImageName"), params)
+	return server.store.newDockerImageVersionDesc(version), nil
+}
+
+func getDockerImages(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var r *repo
+	r, err = server.store.findRepo(req.get("RepoId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, r.id, CanRead)
+	if err != nil { return nil, err }
+	var list = newListDesc()
+	server.store.forEach(func(obj interface{}) {
+		if i, isType := obj.(*dockerImage); isType && (i.repoId == r.id) {
+			list.add(server.store.newDockerImageDesc(i))
+		}
+	})
+	return list, nil
+}
+
+/*******************************************************************************
+ * The Id may be that of an image or of an image version; the desc returned is
+ * of the corresponding type.
+ */
+func getDockerImageDesc(server *Server, req *request) (interface{}, error) {
+	var store = server.store
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var id = req.get("DockerImageId")
+	err = server.requirePermission(u, id, CanRead)
+	if version := store.getDockerImageVersion(id); version != nil {
+		if err != nil { return nil, err }
+		return store.newDockerImageVersionDesc(version), nil
+	}
+	if image := store.getDockerImage(id); image != nil {
+		if err != nil { return nil, err }
+		return store.newDockerImageDesc(image), nil
+	}
+	return nil, notFound("Docker image", id)
+}
+
+func getDockerImageVersions(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var image *dockerImage
+	image, err = server.store.findDockerImage(req.get("DockerImageId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, image.id, CanRead)
+	if err != nil { return nil, err }
+	var list = newListDesc()
+	for _, version := range server.store.getImageVersions(image.id) {
+		list.add(server.store.newDockerImageVersionDesc(version))
+	}
+	return list, nil
+}
+
+func remDockerImage(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var image *dockerImage
+	image, err = server.store.findDockerImage(req.get("ImageId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, image.id, CanDelete)
+	if err != nil { return nil, err }
+	server.store.removeImage(image)
+	return newResult("Docker image removed"), nil
+}
+
+func remImageVersion(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var version = server.store.getDockerImageVersion(req.get("ImageVersionId"))
+	if version == nil { return nil, notFound("Image version", req.get("ImageVersionId")) }
+	err = server.requirePermission(u, version.id, CanDelete)
+	if err != nil { return nil, err }
+	server.store.removeImageVersion(version)
+	return newResult("Image version removed"), nil
+}
+
+func downloadImage(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var version *dockerImageVersion
+	_, version, err = server.store.findImageOrVersion(req.get("ImageObjId"))
+	if err != nil { return nil, err }
+	if version == nil { return nil, fail(http.StatusBadRequest, "Image has no versions") }
+	err = server.requirePermission(u, version.id, CanRead)
+	if err != nil { return nil, err }
+	return version.content, nil
+}
+
+/*******************************************************************************
+ * Return the most recent scan of any version of the image. If the image has not
+ * been scanned, the fields of the status are empty.
+ */
+func getDockerImageStatus(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var image *dockerImage
+	image, _, err = server.store.findImageOrVersion(req.get("ImageObjId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, image.id, CanRead)
+	if err != nil { return nil, err }
+	var status = &imageStatusDesc{
+		baseDesc: newBaseDesc("ImageStatusDesc"),
+		ParameterValueDescs: make([]parameterValue, 0),
+	}
+	server.store.forEach(func(obj interface{}) {
+		if e, isType := obj.(*scanEvent); isType && (e.imageId == image.id) {
+			status.EventId = e.id
+			status.When = formatTime(e.when)
+			status.UserObjId = e.userObjId
+			status.ScanConfigId = e.scanConfigId
+			status.ProviderName = e.providerName
+			status.ParameterValueDescs = append(make([]parameterValue, 0), e.parameterValues...)
+			status.Score = e.score
+		}
+	})
+	return status, nil
+}
+
+/*******************************************************************************
+ * Return the image that an event pertains to, or "".
+ */
+func getEventImageId(obj interface{}) string {
+	switch e := obj.(type) {
+	case *dockerfileExecEvent: return e.imageId
+	case *scanEvent: return e.imageId
+	default: return ""
+	}
+}
+
+func getEventUserObjId(obj interface{}) string {
+	switch e := obj.(type) {
+	case *dockerfileExecEvent: return e.userObjId
+	case *scanEvent: return e.userObjId
+	default: return ""
+	}
+}
+
+/*******************************************************************************
+ * Users may obtain their own events, and the events of images that they can
+ * read.
+ */
+func getEventDesc(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var obj = server.store.objects[req.get("EventId")]
+	var desc = server.store.newEventDesc(obj)
+	if desc == nil { return nil, notFound("Event", req.get("EventId")) }
+	if getEventUserObjId(obj) != u.id {
+		err = server.requirePermission(u, getEventImageId(obj), CanRead)
+		if err != nil { return nil, err }
+	}
+	return desc, nil
+}
+
+/*******************************************************************************
+ * The UserId field may be a user id or a user object Id, in which case the
+ * user's events are returned; or it may be the object Id of an image, in which
+ * case the events of the image are returned.
+ */
+func getUserEvents(server *Server, req *request) (interface{}, error) {
+	var store = server.store
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var id = req.get("UserId")
+	var target = store.getUserByUserId(id)
+	if target == nil { target = store.getUser(id) }
+	var matches func(obj interface{}) bool
+	if target != nil {
+		if target != u {
+			err = server.requirePermission(u, target.id, CanRead)
+			if err != nil { return nil, err }
+		}
+		matches = func(obj interface{}) bool { return getEventUserObjId(obj) == target.id }
+	} else if image := store.getDockerImage(id); image != nil {
+		err = server.requirePermission(u, image.id, CanRead)
+		if err != nil { return nil, err }
+		matches = func(obj interface{}) bool { return getEventImageId(obj) == image.id }
+	} else {
+		return nil, notFound("User", id)
+	}
+	var list = newListDesc()
+	store.forEach(func(obj interface{}) {
+		var desc = store.newEventDesc(obj)
+		if (desc != nil) && matches(obj) { list.add(desc) }
+	})
+	return list, nil
+}
+
+/*******************************************************************************
+ * The Id may be that of an image, or of an image version.
+ */
+func getDockerImageEvents(server *Server, req *request) (interface{}, error) {
+	var store = server.store
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var id = req.get("ImageObjId")
+	_, _, err = store.findImageOrVersion(id)
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, id, CanRead)
+	if err != nil { return nil, err }
+	var list = newListDesc()
+	store.forEach(func(obj interface{}) {
+		var matches bool
+		switch e := obj.(type) {
+		case *dockerfileExecEvent: matches = (e.imageId == id) || (e.imageVersionId == id)
+		case *scanEvent: matches = (e.imageId == id) || (e.imageVersionId == id)
+		}
+		if matches { list.add(store.newEventDesc(obj)) }
+	})
+	return list, nil
+}
+
+func getDockerfileEvents(server *Server, req *request) (interface{}, error) {
+	var store = server.store
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var d *dockerfile
+	d, err = store.findDockerfile(req.get("DockerfileId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, d.id, CanRead)
+	if err != nil { return nil, err }
+	var list = newListDesc()
+	store.forEach(func(obj interface{}) {
+		if e, isType := obj.(*dockerfileExecEvent); isType && (e.dockerfileId == d.id) {
+			list.add(store.newDockerfileExecEventDesc(e))
+		}
+	})
+	return list, nil
+}
+
+/*******************************************************************************
+ * Check a request that names a party and a resource: both must exist, and the
+ * user must have the specified permission on the resource.
+ */
+func (server *Server) checkPermissionRequest(req *request, permission int) (
+	string, string, error) {
+	var u, err = requireUser(req)
+	if err != nil { return "", "", err }
+	var partyId = req.get("PartyId")
+	var resourceId = req.get("ResourceId")
+	if (server.store.getUser(partyId) == nil) && (server.store.getGroup(partyId) == nil) {
+		return "", "", notFound("Party", partyId)
+	}
+	if server.store.objects[resourceId] == nil { return "", "", notFound("Resource", resourceId) }
+	if (permission == CanRead) && (partyId == u.id) { return partyId, resourceId, nil }
+	err = server.requirePermission(u, resourceId, permission)
+	if err != nil { return "", "", err }
+	return partyId, resourceId, nil
+}
+
+/*******************************************************************************
+ * Return the permission mask sent in the CanCreateIn, CanRead, etc. fields.
+ */
+func getMask(req *request) [5]bool {
+	var mask [5]bool
+	for i, name := range permissionNames { mask[i] = (req.get(name) == "true") }
+	return mask
+}
+
+/*******************************************************************************
+ * Replace the party's permissions on the resource.
+ */
+func setPermission(server *Server, req *request) (interface{}, error) {
+	var partyId, resourceId, err = server.checkPermissionRequest(req, CanWrite)
+	if err != nil { return nil, err }
+	var entry = server.store.setACLEntry(partyId, resourceId, getMask(req))
+	return newPermissionDesc(partyId, resourceId, entry), nil
+}
+
+/*******************************************************************************
+ * Add to the party's permissions on the resource.
+ */
+func addPermission(server *Server, req *request) (interface{}, error) {
+	var partyId, resourceId, err = server.checkPermissionRequest(req, CanWrite)
+	if err != nil { return nil, err }
+	var mask = getMask(req)
+	var entry = server.store.getACLEntry(partyId, resourceId)
+	if entry != nil {
+		for i, p := range entry.mask { mask[i] = mask[i] || p }
+	}
+	entry = server.store.setACLEntry(partyId, resourceId, mask)
+	return newPermissionDesc(partyId, resourceId, entry), nil
+}
+
+/*******************************************************************************
+ * Users may obtain their own permissions, and the permissions of others on
+ * resources that they can read. A party that has no entry has no permissions.
+ */
+func getPermission(server *Server, req *request) (interface{}, error) {
+	var partyId, resourceId, err = server.checkPermissionRequest(req, CanRead)
+	if err != nil { return nil, err }
+	return newPermissionDesc(partyId, resourceId,
+		server.store.getACLEntry(partyId, resourceId)), nil
+}
+
+func remPermission(server *Server, req *request) (interface{}, error) {
+	var partyId, resourceId, err = server.checkPermissionRequest(req, CanWrite)
+	if err != nil { return nil, err }
+	server.store.remACLEntry(partyId, resourceId)
+	return newResult("Permission removed"), nil
+}
+
+func getScanProviders(server *Server, req *request) (interface{}, error) {
+	var _, err = requireUser(req)
+	if err != nil { return nil, err }
+	var list = newListDesc()
+	for _, provider := range scanProviders { list.add(provider) }
+	return list, nil
+}
+
+/*******************************************************************************
+ * If a file is posted, it is the image of a new flag, which is shown for images
+ * that pass the scan.
+ */
+func defineScanConfig(server *Server, req *request) (interface{}, error) {
+	var store = server.store
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var values []string
+	values, err = requireValues(req, "Name", "ProviderName")
+	if err != nil { return nil, err }
+	if ! isScanProvider(values[1]) {
+		return nil, fail(http.StatusBadRequest, "Unrecognized scan provider: %s", values[1])
+	}
+	var r *repo
+	r, err = server.getRepoOrDefault(u, req.get("RepoId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, r.id, CanCreateIn)
+	if err != nil { return nil, err }
+	var s = &scanConfig{
+		id: store.createId("scanconfig"),
+		repoId: r.id,
+		name: values[0],
+		description: req.get("Description"),
+		providerName: values[1],
+		successExpression: req.get("SuccessExpression"),
+		parameterValues: getProviderParams(req),
+		creationDate: time.Now(),
+	}
+	if req.fileContent != nil {
+		s.flagId = store.createFlag(u, r.id, s.name, "Flag for " + s.name, req.fileContent).id
+	}
+	store.add(s.id, s)
+	store.grantAll(u, s.id)
+	return store.newScanConfigDesc(s), nil
+}
+
+/*******************************************************************************
+ * Fields that are empty are not changed. Provider parameters, if any are sent,
+ * replace the existing ones.
+ */
+func updateScanConfig(server *Server, req *request) (interface{}, error) {
+	var store = server.store
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var s *scanConfig
+	s, err = store.findScanConfig(req.get("ScanConfigId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, s.id, CanWrite)
+	if err != nil { return nil, err }
+	if req.get("ProviderName") != "" {
+		if ! isScanProvider(req.get("ProviderName")) {
+			return nil, fail(http.StatusBadRequest, "Unrecognized scan provider: %s",
+				req.get("ProviderName"))
+		}
+		s.providerName = req.get("ProviderName")
+	}
+	if req.get("Name") != "" { s.name = req.get("Name") }
+	if req.get("Description") != "" { s.description = req.get("Description") }
+	if req.get("SuccessExpression") != "" { s.successExpression = req.get("SuccessExpression") }
+	var params = getProviderParams(req)
+	if len(params) > 0 { s.parameterValues = params }
+	if req.fileContent != nil {
+		s.flagId = store.createFlag(u, s.repoId, s.name, "Flag for " + s.name, req.fileContent).id
+	}
+	return store.newScanConfigDesc(s), nil
+}
+
+func getScanConfigDesc(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var s *scanConfig
+	s, err = server.store.findScanConfig(req.get("ScanConfigId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, s.id, CanRead)
+	if err != nil { return nil, err }
+	return server.store.newScanConfigDesc(s), nil
+}
+
+func getScanConfigDescByName(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var r *repo
+	r, err = server.store.findRepo(req.get("RepoId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, r.id, CanRead)
+	if err != nil { return nil, err }
+	var result *scanConfig
+	server.store.forEach(func(obj interface{}) {
+		if s, isType := obj.(*scanConfig); isType && (s.repoId == r.id) &&
+			(s.name == req.get("ScanConfigName")) { result = s }
+	})
+	if result == nil { return nil, notFound("Scan config", req.get("ScanConfigName")) }
+	return server.store.newScanConfigDesc(result), nil
+}
+
+func remScanConfig(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var s *scanConfig
+	s, err = server.store.findScanConfig(req.get("ScanConfigId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, s.id, CanDelete)
+	if err != nil { return nil, err }
+	server.store.removeScanConfig(s)
+	return newResult("Scan config removed"), nil
+}
+
+func useScanConfigForImage(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var image *dockerImage
+	image, err = server.store.findDockerImage(req.get("DockerImageId"))
+	if err != nil { return nil, err }
+	var s *scanConfig
+	s, err = server.store.findScanConfig(req.get("ScanConfigId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, image.id, CanWrite)
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, s.id, CanRead)
+	if err != nil { return nil, err }
+	for _, id := range image.scanConfigIds {
+		if id == s.id { return newResult("Image already uses the scan config"), nil }
+	}
+	image.scanConfigIds = append(image.scanConfigIds, s.id)
+	return newResult("Image now uses the scan config"), nil
+}
+
+func stopUsingScanConfigForImage(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var image *dockerImage
+	image, err = server.store.findDockerImage(req.get("DockerImageId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, image.id, CanWrite)
+	if err != nil { return nil, err }
+	var scanConfigIds = make([]string, 0)
+	for _, id := range image.scanConfigIds {
+		if id != req.get("ScanConfigId") { scanConfigIds = append(scanConfigIds, id) }
+	}
+	if len(scanConfigIds) == len(image.scanConfigIds) {
+		return nil, fail(http.StatusBadRequest, "Image does not use scan config %s",
+			req.get("ScanConfigId"))
+	}
+	image.scanConfigIds = scanConfigIds
+	return newResult("Image no longer uses the scan config"), nil
+}
+
+/*******************************************************************************
+ * Scan an image (its most recent version) or an image version, with each of
+ * the scan configs listed in ScanConfigId (comma-separated), or - if none are
+ * listed - with each of the scan configs that the image uses. No
+ * vulnerabilities are ever found.
+ */
+func scanImage(server *Server, req *request) (interface{}, error) {
+	var store = server.store
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var image *dockerImage
+	var version *dockerImageVersion
+	image, version, err = store.findImageOrVersion(req.get("ImageObjId"))
+	if err != nil { return nil, err }
+	if version == nil { return nil, fail(http.StatusBadRequest, "Image has no versions") }
+	err = server.requirePermission(u, version.id, CanRead)
+	if err != nil { return nil, err }
+
+	var scanConfigIds = image.scanConfigIds
+	var scanConfigIdList string
+	scanConfigIdList, err = url.QueryUnescape(req.get("ScanConfigId"))
+	if err != nil { return nil, fail(http.StatusBadRequest, "%s", err.Error()) }
+	if scanConfigIdList != "" { scanConfigIds = strings.Split(scanConfigIdList, ",") }
+	if len(scanConfigIds) == 0 {
+		return nil, fail(http.StatusBadRequest, "No ScanConfigId was given, and the image uses none")
+	}
+
+	var list = newListDesc()
+	for _, scanConfigId := range scanConfigIds {
+		var s *scanConfig
+		s, err = store.findScanConfig(strings.TrimSpace(scanConfigId))
+		if err != nil { return nil, err }
+		err = server.requirePermission(u, s.id, CanExecute)
+		if err != nil { return nil, err }
+		var event = &scanEvent{
+			id: store.createId("event"),
+			when: time.Now(),
+			userObjId: u.id,
+			scanConfigId: s.id,
+			providerName: s.providerName,
+			parameterValues: s.parameterValues,
+			imageId: image.id,
+			imageVersionId: version.id,
+			score: "0",
+		}
+		store.add(event.id, event)
+		list.add(store.newScanEventDesc(event))
+	}
+	return list, nil
+}
+
+func defineFlag(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var values []string
+	values, err = requireValues(req, "Name")
+	if err != nil { return nil, err }
+	var r *repo
+	r, err = server.getRepoOrDefault(u, req.get("RepoId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, r.id, CanCreateIn)
+	if err != nil { return nil, err }
+	err = requireFile(req)
+	if err != nil { return nil, err }
+	var f = server.store.createFlag(u, r.id, values[0], req.get("Description"), req.fileContent)
+	return server.store.newFlagDesc(f), nil
+}
+
+func getFlagDesc(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var f *flag
+	f, err = server.store.findFlag(req.get("FlagId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, f.id, CanRead)
+	if err != nil { return nil, err }
+	return server.store.newFlagDesc(f), nil
+}
+
+func getFlagDescByName(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var r *repo
+	r, err = server.store.findRepo(req.get("RepoId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, r.id, CanRead)
+	if err != nil { return nil, err }
+	var result *flag
+	server.store.forEach(func(obj interface{}) {
+		if f, isType := obj.(*flag); isType && (f.repoId == r.id) &&
+			(f.name == req.get("FlagName")) { result = f }
+	})
+	if result == nil { return nil, notFound("Flag", req.get("FlagName")) }
+	return server.store.newFlagDesc(result), nil
+}
+
+func getFlagImage(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var f *flag
+	f, err = server.store.findFlag(req.get("FlagId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, f.id, CanRead)
+	if err != nil { return nil, err }
+	return f.image, nil
+}
+
+/*******************************************************************************
+ * A flag that a scan config uses cannot be removed.
+ */
+func remFlag(server *Server, req *request) (interface{}, error) {
+	var u, err = requireUser(req)
+	if err != nil { return nil, err }
+	var f *flag
+	f, err = server.store.findFlag(req.get("FlagId"))
+	if err != nil { return nil, err }
+	err = server.requirePermission(u, f.id, CanDelete)
+	if err != nil { return nil, err }
+	var usedBy = ""
+	server.store.forEach(func(obj interface{}) {
+		if s, isType := obj.(*scanConfig); isType && (s.flagId == f.id) { usedBy = s.name }
+	})
+	if usedBy != "" {
+		return nil, fail(http.StatusConflict, "Flag %s is used by scan config %s", f.name, usedBy)
+	}
+	server.store.remove(f.id)
+	return newResult("Flag removed"), nil
+}
+
+func enableEmailVerification(server *Server, req *request) (interface{}, error) {
+	var _, err = requireUser(req)
+	if err != nil { return nil, err }
+	server.store.emailVerificationEnabled = (req.get("VerificationEnabled") == "true")
+	return newResult("Email verification setting changed"), nil
+}
+
+/*******************************************************************************
+ * Tokens are issued by createUser, when email verification is enabled.
+ */
+func validateAccountVerificationToken(server *Server, req *request) (interface{}, error) {
+	var token = req.get("AccountVerificationToken")
+	if server.store.verificationTokens[token] == "" { return nil, notFound("Token", token) }
+	delete(server.store.verificationTokens, token)
+	return newResult("Account verified"), nil
+}
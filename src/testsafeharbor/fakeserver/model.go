@@ -0,0 +1,402 @@
+/*******************************************************************************
+ * The in-memory state of the fake server: realms, users, groups, repos, and the
+ * things that repos contain, plus access control entries, sessions, and events.
+ * All objects share one Id space, so that a request that names an object of
+ * unknown kind (e.g., a resource to which a permission applies) can find it.
+ */
+
+package fakeserver
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	CanCreateIn = iota
+	CanRead
+	CanWrite
+	CanExecute
+	CanDelete
+)
+
+type realm struct {
+	id string
+	name string
+	orgFullName string
+	description string
+	adminUserId string
+	active bool
+	creationDate time.Time
+}
+
+type user struct {
+	id string
+	userId string
+	name string
+	emailAddress string
+	password string
+	realmId string
+	enabled bool
+	defaultRepoId string
+	creationDate time.Time
+}
+
+type group struct {
+	id string
+	realmId string
+	name string
+	description string
+	userObjIds []string
+	creationDate time.Time
+}
+
+type repo struct {
+	id string
+	realmId string
+	name string
+	description string
+	creationDate time.Time
+}
+
+type dockerfile struct {
+	id string
+	repoId string
+	name string
+	description string
+	content []byte
+	creationDate time.Time
+}
+
+type dockerImage struct {
+	id string
+	repoId string
+	name string
+	description string
+	scanConfigIds []string  // scan configs that the image uses
+	noOfVersionsCreated int
+	creationDate time.Time
+}
+
+type dockerImageVersion struct {
+	id string
+	imageId string
+	version string
+	creationEventId string
+	content []byte  // what downloadImage returns
+	buildOutput string
+	creationDate time.Time
+}
+
+type scanConfig struct {
+	id string
+	repoId string
+	name string
+	description string
+	providerName string
+	successExpression string
+	flagId string
+	parameterValues []parameterValue
+	creationDate time.Time
+}
+
+type flag struct {
+	id string
+	repoId string
+	name string
+	description string
+	image []byte
+	creationDate time.Time
+}
+
+type parameterValue struct {
+	Name string
+	Value string
+}
+
+type dockerfileExecEvent struct {
+	id string
+	when time.Time
+	userObjId string
+	dockerfileId string  // "" once the dockerfile is removed
+	imageId string
+	imageVersionId string  // "" once the image version is removed
+	parameterValues []parameterValue
+	dockerfileContent string
+}
+
+type scanEvent struct {
+	id string
+	when time.Time
+	userObjId string
+	scanConfigId string
+	providerName string
+	parameterValues []parameterValue
+	imageId string
+	imageVersionId string
+	score string
+}
+
+type aclEntry struct {
+	id string
+	partyId string  // a user or a group
+	resourceId string
+	mask [5]bool  // indexed by CanCreateIn, CanRead, etc.
+}
+
+type store struct {
+	nextId int
+	objects map[string]interface{}  // by Id
+	ids []string  // in order of creation, including Ids of removed objects
+	aclEntries map[string]*aclEntry  // by partyId + "/" + resourceId
+	sessions map[string]string  // user object Id, by session Id
+	emailVerificationEnabled bool
+	verificationTokens map[string]string  // user object Id, by token
+}
+
+func newStore() *store {
+	return &store{
+		objects: make(map[string]interface{}),
+		ids: make([]string, 0),
+		aclEntries: make(map[string]*aclEntry),
+		sessions: make(map[string]string),
+		verificationTokens: make(map[string]string),
+	}
+}
+
+/*******************************************************************************
+ * Return a new Id, unique within the store. The prefix makes Ids easier to
+ * recognize in test output.
+ */
+func (store *store) createId(prefix string) string {
+	store.nextId++
+	return fmt.Sprintf("%s%06d", prefix, store.nextId)
+}
+
+/*******************************************************************************
+ * Add an object to the store, under the specified Id.
+ */
+func (store *store) add(id string, obj interface{}) {
+	store.objects[id] = obj
+	store.ids = append(store.ids, id)
+}
+
+/*******************************************************************************
+ * Remove an object, and any access control entries that refer to it.
+ */
+func (store *store) remove(id string) {
+	delete(store.objects, id)
+	for key, entry := range store.aclEntries {
+		if (entry.partyId == id) || (entry.resourceId == id) { delete(store.aclEntries, key) }
+	}
+}
+
+/*******************************************************************************
+ * Call the function for each object in the store, in order of creation.
+ */
+func (store *store) forEach(f func(obj interface{})) {
+	for _, id := range store.ids {
+		var obj, exists = store.objects[id]
+		if exists { f(obj) }
+	}
+}
+
+func (store *store) getRealm(id string) *realm {
+	var r, _ = store.objects[id].(*realm)
+	return r
+}
+
+func (store *store) getUser(id string) *user {
+	var u, _ = store.objects[id].(*user)
+	return u
+}
+
+func (store *store) getGroup(id string) *group {
+	var g, _ = store.objects[id].(*group)
+	return g
+}
+
+func (store *store) getRepo(id string) *repo {
+	var r, _ = store.objects[id].(*repo)
+	return r
+}
+
+func (store *store) getDockerfile(id string) *dockerfile {
+	var d, _ = store.objects[id].(*dockerfile)
+	return d
+}
+
+func (store *store) getDockerImage(id string) *dockerImage {
+	var i, _ = store.objects[id].(*dockerImage)
+	return i
+}
+
+func (store *store) getDockerImageVersion(id string) *dockerImageVersion {
+	var v, _ = store.objects[id].(*dockerImageVersion)
+	return v
+}
+
+func (store *store) getScanConfig(id string) *scanConfig {
+	var s, _ = store.objects[id].(*scanConfig)
+	return s
+}
+
+func (store *store) getFlag(id string) *flag {
+	var f, _ = store.objects[id].(*flag)
+	return f
+}
+
+/*******************************************************************************
+ * Return the realm that has the specified name, or nil.
+ */
+func (store *store) getRealmByName(name string) *realm {
+	var result *realm
+	store.forEach(func(obj interface{}) {
+		if r, isType := obj.(*realm); isType && (r.name == name) { result = r }
+	})
+	return result
+}
+
+/*******************************************************************************
+ * Return the user that has the specified user id (not object Id), or nil.
+ */
+func (store *store) getUserByUserId(userId string) *user {
+	var result *user
+	store.forEach(func(obj interface{}) {
+		if u, isType := obj.(*user); isType && (u.userId == userId) { result = u }
+	})
+	return result
+}
+
+/*******************************************************************************
+ * Return the user that is logged in under the specified session, or nil.
+ */
+func (store *store) getSessionUser(sessionId string) *user {
+	if sessionId == "" { return nil }
+	var u = store.getUser(store.sessions[sessionId])
+	if (u == nil) || (! u.enabled) { return nil }
+	return u
+}
+
+/*******************************************************************************
+ * Return the versions of the specified image, in order of creation.
+ */
+func (store *store) getImageVersions(imageId string) []*dockerImageVersion {
+	var versions = make([]*dockerImageVersion, 0)
+	store.forEach(func(obj interface{}) {
+		if v, isType := obj.(*dockerImageVersion); isType && (v.imageId == imageId) {
+			versions = append(versions, v)
+		}
+	})
+	return versions
+}
+
+/*******************************************************************************
+ * Return the Id of the object that contains the specified object, or "" if it
+ * is not contained in anything. Permissions are inherited along this chain.
+ */
+func (store *store) getParentId(id string) string {
+	switch obj := store.objects[id].(type) {
+	case *user: return obj.realmId
+	case *group: return obj.realmId
+	case *repo: return obj.realmId
+	case *dockerfile: return obj.repoId
+	case *dockerImage: return obj.repoId
+	case *dockerImageVersion: return obj.imageId
+	case *scanConfig: return obj.repoId
+	case *flag: return obj.repoId
+	default: return ""
+	}
+}
+
+/*******************************************************************************
+ * Return the Ids of the parties whose access control entries apply to the
+ * user: the user, and the groups that the user belongs to.
+ */
+func (store *store) getPartyIds(u *user) []string {
+	var partyIds = []string{u.id}
+	store.forEach(func(obj interface{}) {
+		if g, isType := obj.(*group); isType {
+			for _, userObjId := range g.userObjIds {
+				if userObjId == u.id { partyIds = append(partyIds, g.id) }
+			}
+		}
+	})
+	return partyIds
+}
+
+func (store *store) getACLEntry(partyId, resourceId string) *aclEntry {
+	return store.aclEntries[partyId + "/" + resourceId]
+}
+
+/*******************************************************************************
+ * Set the access control entry for the party and resource, creating the entry
+ * if it does not exist.
+ */
+func (store *store) setACLEntry(partyId, resourceId string, mask [5]bool) *aclEntry {
+	var entry = store.getACLEntry(partyId, resourceId)
+	if entry == nil {
+		entry = &aclEntry{
+			id: store.createId("acl"),
+			partyId: partyId,
+			resourceId: resourceId,
+		}
+		store.aclEntries[partyId + "/" + resourceId] = entry
+	}
+	entry.mask = mask
+	return entry
+}
+
+func (store *store) remACLEntry(partyId, resourceId string) {
+	delete(store.aclEntries, partyId + "/" + resourceId)
+}
+
+/*******************************************************************************
+ * Give the creator of an object all permissions on it.
+ */
+func (store *store) grantAll(u *user, resourceId string) {
+	store.setACLEntry(u.id, resourceId, [5]bool{true, true, true, true, true})
+}
+
+/*******************************************************************************
+ * Return true if the user has the permission on the resource, either directly,
+ * via a group, or via an object that contains the resource.
+ */
+func (store *store) hasPermission(u *user, resourceId string, permission int) bool {
+	var partyIds = store.getPartyIds(u)
+	for id := resourceId; id != ""; id = store.getParentId(id) {
+		for _, partyId := range partyIds {
+			var entry = store.getACLEntry(partyId, id)
+			if (entry != nil) && entry.mask[permission] { return true }
+		}
+	}
+	return false
+}
+
+/*******************************************************************************
+ * Return true if the user, or a group that the user belongs to, has any
+ * permission on the resource itself. This is what makes a resource one of the
+ * user's own, for the getMy... requests.
+ */
+func (store *store) hasAnyDirectPermission(u *user, resourceId string) bool {
+	for _, partyId := range store.getPartyIds(u) {
+		var entry = store.getACLEntry(partyId, resourceId)
+		if entry == nil { continue }
+		for _, p := range entry.mask { if p { return true } }
+	}
+	return false
+}
+
+/*******************************************************************************
+ * Return the Ids of the realms that the user can modify.
+ */
+func (store *store) getRealmsUserCanModify(u *user) []string {
+	var realmIds = make([]string, 0)
+	store.forEach(func(obj interface{}) {
+		if r, isType := obj.(*realm); isType && r.active {
+			var entry = store.getACLEntry(u.id, r.id)
+			if (entry != nil) && entry.mask[CanWrite] { realmIds = append(realmIds, r.id) }
+		}
+	})
+	return realmIds
+}
@@ -0,0 +1,228 @@
+/*******************************************************************************
+ * An in-process, in-memory stand-in for the SafeHarbor server, for testing the
+ * test harness itself without deploying a server. It implements the REST
+ * requests that the Try* methods of helpers.TestContext send, and honors realm,
+ * user, group and access control semantics closely enough that the
+ * CreateRealmsAndUsers, AccessControl, GetMy and Delete suites pass against it.
+ * It does not run docker or any scanners: building an image records a new image
+ * version, and a scan finds no vulnerabilities.
+ *
+ * Usage:
+ *	var server = fakeserver.NewServer()
+ *	var err = server.Start("127.0.0.1:0")
+ *	... send requests to server.GetPort() ...
+ *	server.Close()
+ */
+
+package fakeserver
+
+import (
+	"fmt"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"encoding/json"
+)
+
+type Server struct {
+	Verbose bool  // if true, print each request
+	lock sync.Mutex
+	listener net.Listener
+	store *store
+	handlers map[string]handlerFunc
+}
+
+/*******************************************************************************
+ * A request, after its form (or multipart form) values and its session have
+ * been extracted.
+ */
+type request struct {
+	name string
+	values url.Values
+	fileName string  // base name of the file posted, if any
+	fileContent []byte  // nil if no file was posted
+	sessionId string
+	user *user  // the authenticated user, or nil
+}
+
+/*******************************************************************************
+ * Return the value of the named form field, or "" if it was not sent.
+ */
+func (req *request) get(name string) string {
+	return req.values.Get(name)
+}
+
+/*******************************************************************************
+ * A handler returns either an object to send as JSON, a []byte to send as the
+ * raw content of the response, or an error. An error that is not a *failure is
+ * reported as an internal server error.
+ */
+type handlerFunc func(server *Server, req *request) (interface{}, error)
+
+type failure struct {
+	status int
+	message string
+}
+
+func (f *failure) Error() string {
+	return f.message
+}
+
+func fail(status int, format string, args ...interface{}) error {
+	return &failure{ status: status, message: fmt.Sprintf(format, args...) }
+}
+
+func NewServer() *Server {
+	var server = &Server{
+		store: newStore(),
+		handlers: make(map[string]handlerFunc),
+	}
+	server.registerHandlers()
+	return server
+}
+
+/*******************************************************************************
+ * Begin serving on the specified address, e.g., "127.0.0.1:0" for any free
+ * local port.
+ */
+func (server *Server) Start(address string) error {
+	var err error
+	server.listener, err = net.Listen("tcp", address)
+	if err != nil { return err }
+	go http.Serve(server.listener, server)
+	return nil
+}
+
+/*******************************************************************************
+ * Return the port that the server is listening on.
+ */
+func (server *Server) GetPort() int {
+	return server.listener.Addr().(*net.TCPAddr).Port
+}
+
+/*******************************************************************************
+ *
+ */
+func (server *Server) Close() error {
+	if server.listener == nil { return errors.New("Server was not started") }
+	return server.listener.Close()
+}
+
+/*******************************************************************************
+ * Discard all state, as the clearAll request does.
+ */
+func (server *Server) Reset() {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	server.store = newStore()
+}
+
+/*******************************************************************************
+ * Handle one REST request. The request name is the path of the URL.
+ */
+func (server *Server) ServeHTTP(writer http.ResponseWriter, httpReq *http.Request) {
+
+	var req = &request{ name: strings.TrimPrefix(httpReq.URL.Path, "/") }
+	var handler = server.handlers[req.name]
+	if handler == nil {
+		server.writeFailure(writer, fail(http.StatusNotFound, "Unrecognized request: %s", req.name))
+		return
+	}
+
+	var err = parseRequest(httpReq, req)
+	if err != nil {
+		server.writeFailure(writer, fail(http.StatusBadRequest, "%s", err.Error()))
+		return
+	}
+	if server.Verbose {
+		fmt.Println(fmt.Sprintf("fakeserver: %s %s", req.name, req.get("Log")))
+	}
+
+	var result interface{}
+	func() {
+		server.lock.Lock()
+		defer server.lock.Unlock()
+		req.user = server.store.getSessionUser(req.sessionId)
+		result, err = handler(server, req)
+	}()
+	if err != nil {
+		server.writeFailure(writer, err)
+		return
+	}
+
+	if bytes, isType := result.([]byte); isType {
+		writer.Header().Set("Content-Type", "application/octet-stream")
+		writer.WriteHeader(http.StatusOK)
+		writer.Write(bytes)
+		return
+	}
+	var bytes []byte
+	bytes, err = json.Marshal(result)
+	if err != nil {
+		server.writeFailure(writer, err)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(bytes)
+}
+
+/*******************************************************************************
+ * Extract the form values, any posted file, and the session Id. The session Id
+ * is sent in a cookie, or - by addAndExecDockerfile - as a form value.
+ */
+func parseRequest(httpReq *http.Request, req *request) error {
+
+	var err error
+	if strings.HasPrefix(httpReq.Header.Get("Content-Type"), "multipart/form-data") {
+		err = httpReq.ParseMultipartForm(32 << 20)
+		if err != nil { return err }
+		for _, fileHeaders := range httpReq.MultipartForm.File {
+			if len(fileHeaders) == 0 { continue }
+			var file, err = fileHeaders[0].Open()
+			if err != nil { return err }
+			req.fileContent, err = ioutil.ReadAll(file)
+			file.Close()
+			if err != nil { return err }
+			req.fileName = fileHeaders[0].Filename
+			break
+		}
+	} else {
+		err = httpReq.ParseForm()
+		if err != nil { return err }
+	}
+	req.values = httpReq.Form
+
+	var cookie *http.Cookie
+	cookie, err = httpReq.Cookie("SessionId")
+	if err == nil {
+		req.sessionId = cookie.Value
+	} else {
+		req.sessionId = req.get("SessionId")
+	}
+	return nil
+}
+
+/*******************************************************************************
+ * Send an error response. The body has the same form as the SafeHarbor
+ * server's failure responses.
+ */
+func (server *Server) writeFailure(writer http.ResponseWriter, err error) {
+	var status = http.StatusInternalServerError
+	if f, isType := err.(*failure); isType { status = f.status }
+	if server.Verbose {
+		fmt.Println(fmt.Sprintf("fakeserver: returning %d: %s", status, err.Error()))
+	}
+	var bytes, _ = json.Marshal(baseDesc{
+		HTTPStatusCode: status,
+		HTTPReasonPhrase: err.Error(),
+		ObjectType: "FailureDesc",
+	})
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	writer.Write(bytes)
+}
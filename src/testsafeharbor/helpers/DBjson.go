@@ -4,17 +4,22 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"strconv"
+	"regexp"
 	"errors"
 	"time"
-	"runtime/debug"
-	
-	"rest"
+	"math/big"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 /*******************************************************************************
  * Construct an object as defined by the specified JSON string. Returns the
- * name of the object type and the object, or an error. The target is the
- * object that has the NewXYZ method for constructing the object.
+ * name of the object type and the object, or an error. Each object is
+ * constructed by the factory registered for its type (see RegisterType) or, if
+ * there is none, by the target's NewXYZ method; the target may be nil if every
+ * type is registered with a factory.
  */
 func ReconstituteObject(target interface{}, json string) (string, interface{}, error) {
 	var typeName string
@@ -23,18 +28,129 @@ func ReconstituteObject(target interface{}, json string) (string, interface{}, e
 	typeName, remainder, err = retrieveTypeName(json)
 	if err != nil { return typeName, nil, err }
 	
-	var methodName = "New" + typeName
-	var method = reflect.ValueOf(target).MethodByName(methodName)
-	if err != nil { return typeName, nil, err }
-	
+	var pos int = len(json) - len(remainder)  // so that errors give positions in json
+	var fieldNames []string
 	var argAr []reflect.Value
-	argAr, err = parseJSON(remainder)
+	fieldNames, argAr, err = parseJSON_obj_value(target, json, &pos)
 	if err != nil { return typeName, nil, err }
-	fmt.Println("argAr has " + fmt.Sprintf("%d", len(argAr)) + " elements")
+	if argAr == nil { return typeName, nil, parseJSON_syntaxError(json, &pos,
+		"While looking for the fields of a " + typeName, "{") }
 
+	var retValue reflect.Value
+	retValue, err = constructObject(target, typeName, fieldNames, argAr)
+	if err != nil { return typeName, nil, err }
+	return typeName, retValue.Interface(), nil
+}
+
+/*******************************************************************************
+ * Call the factory of typeName - the registered one (see RegisterType) or, if
+ * there is none, the target's New<typeName> method - with the specified field
+ * values, and return the object that it returns. If the factory's parameters
+ * have been registered, each field is passed as the parameter of the same
+ * name; otherwise, the fields are passed in order.
+ */
+func constructObject(target interface{}, typeName string, fieldNames []string,
+	argAr []reflect.Value) (reflect.Value, error) {
+	
+	var value reflect.Value
+	var methodName = "New" + typeName
+	var method, params, err = findFactory(target, typeName)
+	if err != nil { return value, err }
+	
+	if params != nil {
+		argAr, err = bindArgs(typeName, params, method.Type(), fieldNames, argAr)
+		if err != nil { return value, err }
+	} else {
+		if method.Type().NumIn() != len(argAr) { return value, errors.New(fmt.Sprintf(
+			"%s takes %d arguments; found %d fields", methodName, method.Type().NumIn(), len(argAr))) }
+		
+		for i, arg := range argAr {
+			argAr[i], err = convertArg(arg, method.Type().In(i))
+			if err != nil { return value, errors.New(fmt.Sprintf(
+				"Argument %d of %s: %s", (i+1), methodName, err.Error())) }
+		}
+	}
+	
 	var retValues []reflect.Value = method.Call(argAr)
-	var retValue0 interface{} = retValues[0].Interface()
-	return typeName, retValue0, nil
+	if len(retValues) == 0 { return value, errors.New(methodName + " returns no value") }
+	return retValues[0], nil
+}
+
+/*******************************************************************************
+ * Convert a parsed field value to the type of the constructor parameter to which
+ * it is passed. null becomes the zero value of the parameter type - nil for a
+ * pointer or interface. A number becomes any numeric type that can hold it, or
+ * a *big.Int if it is an integer that is too large for an int. An array is
+ * converted element by element to the parameter's slice type. A value of type
+ * T can also be passed as a *T, for optional fields.
+ */
+func convertArg(arg reflect.Value, paramType reflect.Type) (reflect.Value, error) {
+	
+	if parseJSON_isNull(arg) { return reflect.Zero(paramType), nil }
+	if arg.Type().AssignableTo(paramType) { return arg, nil }
+	
+	var bigIntType = reflect.TypeOf((*big.Int)(nil))
+	if arg.Type() == bigIntType {
+		var n = arg.Interface().(*big.Int)
+		switch paramType.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if n.IsInt64() && ! reflect.Zero(paramType).OverflowInt(n.Int64()) {
+				return reflect.ValueOf(n.Int64()).Convert(paramType), nil
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if n.IsUint64() && ! reflect.Zero(paramType).OverflowUint(n.Uint64()) {
+				return reflect.ValueOf(n.Uint64()).Convert(paramType), nil
+			}
+		case reflect.Float32, reflect.Float64:
+			var f, _ = new(big.Float).SetInt(n).Float64()
+			return reflect.ValueOf(f).Convert(paramType), nil
+		}
+	}
+	
+	switch arg.Kind() {
+	case reflect.Int:
+		var n = arg.Int()
+		switch paramType.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if ! reflect.Zero(paramType).OverflowInt(n) { return arg.Convert(paramType), nil }
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if (n >= 0) && ! reflect.Zero(paramType).OverflowUint(uint64(n)) {
+				return arg.Convert(paramType), nil
+			}
+		case reflect.Float32, reflect.Float64:
+			return arg.Convert(paramType), nil
+		}
+		if paramType == bigIntType { return reflect.ValueOf(big.NewInt(n)), nil }
+	case reflect.Float64:
+		if paramType.Kind() == reflect.Float32 { return arg.Convert(paramType), nil }
+	case reflect.Slice:
+		if paramType.Kind() == reflect.Slice {
+			var slice = reflect.MakeSlice(paramType, arg.Len(), arg.Len())
+			for i := 0; i < arg.Len(); i++ {
+				var element = arg.Index(i)
+				if (element.Kind() == reflect.Interface) && ! element.IsNil() {
+					element = element.Elem()
+				}
+				var converted, err = convertArg(element, paramType.Elem())
+				if err != nil { return arg, errors.New(fmt.Sprintf(
+					"Element %d: %s", i, err.Error())) }
+				slice.Index(i).Set(converted)
+			}
+			return slice, nil
+		}
+	}
+	
+	if paramType.Kind() == reflect.Ptr {
+		var elemValue, err = convertArg(arg, paramType.Elem())
+		if err == nil {
+			var ptr = reflect.New(paramType.Elem())
+			ptr.Elem().Set(elemValue)
+			return ptr, nil
+		}
+	}
+	
+	return arg, errors.New(fmt.Sprintf("Value %v of type %s cannot be passed as a %s",
+		arg.Interface(), arg.Type().String(), paramType.String()))
 }
 
 /*******************************************************************************
@@ -49,24 +165,28 @@ func ReconstituteObject(target interface{}, json string) (string, interface{}, e
  */
 func retrieveTypeName(json string) (typeName string, remainder string, err error) {
 	
-	var i = strings.Index(json, "\"")
-	var s2 = json[i+1:]
-	var j = strings.Index(s2, "\"")
-	if j == -1 { return "", "", errors.New(
-		fmt.Sprintf("Ill-formatted json: no \" found after pos %d", i)) }
-	var s3 = s2[:j]
+	var pos = 0
+	var token = parseJSON_findNextToken(json, &pos)
+	if token != "\"" { return "", "", parseJSON_tokenError(token, json, &pos,
+		"While looking for a type name", "\"") }
+	typeName, err = parseJSON_field_name(json, &pos)
+	if err != nil { return "", "", err }
 	
-	var k = strings.Index(s2[j:], ":")
-	if k == -1 { return "", "", errors.New(
-		fmt.Sprintf("Ill-formatted json: no : found after position %d", j)) }
+	token = parseJSON_findNextToken(json, &pos)
+	if token != ":" { return typeName, "", parseJSON_tokenError(token, json, &pos,
+		"While looking for colon following the type name", ":") }
 	
-	return s3, s2[j+k+1:], nil
+	return typeName, json[pos:], nil
 }
 
 /*******************************************************************************
  * Parse each json field and return a Value for each.
  * Only built-in types are allowed in the JSON fields, including byte and time.Time.
- * Arrays of these are allowed as well. Recursive.
+ * Arrays of these are allowed as well, and need not be of a single type. Recursive.
+ * An object nested in a field, or in an array in a field, is of the type named
+ * by the field, and is constructed by ReconstituteObject (see
+ * parseJSON_nested_obj_value), so parseJSON, which has no target, does not
+ * accept one.
  * A nil result indicates that no object was found, but no syntax error either.
  * An empty array means that an object was found but it contained no fields.
  * Note: This function is not intended to be a general purpose JSON parser -
@@ -75,62 +195,78 @@ func retrieveTypeName(json string) (typeName string, remainder string, err error
 	obj_value		::= '{'  field  [ comma_field ]  '}'
 	field			::=	'"' (no spaces) field_name (no spaces) '"'  ':'  value
 	field_name		::= <char>+
-	value			::= array_value | simple_value
+	value			::= array_value | simple_value | obj_value
 	comma_field		::= ','  field  [ comma_field ]
 	array_value		::= '['  [ value  [ comma_value ] ]  ']'
 	comma_value		::= ','  value  [ comma_value ]
-	simple_value	::= number | string_value | bool_value | time_value
+	simple_value	::= number | string_value | bool_value | time_value | null_value
+	number			::= [ '-' ] int [ '.' <digit>+ ] [ ( 'e' | 'E' ) [ '+' | '-' ] <digit>+ ]
+	int				::= '0' | <nonzero digit> <digit>*
 	string_value	::= '"' <char>* '"'
 	bool_value		::= 'true' | 'false'
+	null_value		::= 'null'
 	time_value		::= 'time' '"' <char_seq in time format> '"'
  */
 func parseJSON(json string) ([]reflect.Value, error) {
 	
 	var pos int = 0
-	return parseJSON_obj_value(json, &pos)
+	var values []reflect.Value
+	var err error
+	_, values, err = parseJSON_obj_value(nil, json, &pos)
+	return values, err
 }
 
 /*******************************************************************************
  * Parse a JSON object, delimited by { and }. If none found due to EOF, or if
  * the first token does not match the possible values for an obj_value, return nil.
- * Otherwise, return an array of the field values. If there is a syntax error,
- * return an error.
+ * Otherwise, return an array of the field names and an array of the field values.
+ * If there is a syntax error, return an error.
  */
-func parseJSON_obj_value(json string, pos *int) ([]reflect.Value, error) {
+func parseJSON_obj_value(target interface{}, json string, pos *int) ([]string, []reflect.Value, error) {
 	
 	var token string = parseJSON_findNextToken(json, pos)
-	if token == "" { return nil, nil }
+	if token == "" { return nil, nil, nil }
 	
 	if token != "{" {
 		parseJSON_pushTokenBack(token, pos)
-		return nil, nil
+		return nil, nil, nil
 	}
 	
 	var err error
+	var names []string = make([]string, 0)
 	var values []reflect.Value = make([]reflect.Value, 0)
+	var fieldName string
 	var fieldValue reflect.Value
-	_, fieldValue, err = parseJSON_field(json, pos)
-	if err != nil { return values, err }
-	if ! fieldValue.IsValid() { return values, nil } // no fields
+	fieldName, fieldValue, err = parseJSON_field(target, json, pos)
+	if err != nil { return names, values, err }
+	if ! fieldValue.IsValid() { // no fields
+		token = parseJSON_findNextToken(json, pos)
+		if token != "}" { return names, values, parseJSON_tokenError(token, json, pos,
+			"while looking for object terminator", "\"", "}") }
+		return names, values, nil
+	}
 	
+	names = append(names, fieldName)
 	values = append(values, fieldValue)
 	
 	// Add additional fields, if any.
+	var addlFieldNames []string
 	var addlFieldValues []reflect.Value
-	addlFieldValues, err = parseJSON_comma_field(json, pos)
-	if err != nil { return values, err }
+	addlFieldNames, addlFieldValues, err = parseJSON_comma_field(target, json, pos)
+	if err != nil { return names, values, err }
 	if addlFieldValues != nil {
+		names = append(names, addlFieldNames...)
 		values = append(values, addlFieldValues...)
 	}
 	
 	token = parseJSON_findNextToken(json, pos)
-	if token != "}" { return values, parseJSON_tokenError(token, json, pos,
-		"while looking for object terminator") }
+	if token != "}" { return names, values, parseJSON_tokenError(token, json, pos,
+		"while looking for object terminator", ",", "}") }
 	
-	return values, nil
+	return names, values, nil
 }
 
-func parseJSON_field(json string, pos *int) (string, reflect.Value, error) {
+func parseJSON_field(target interface{}, json string, pos *int) (string, reflect.Value, error) {
 	
 	var value reflect.Value
 
@@ -146,83 +282,123 @@ func parseJSON_field(json string, pos *int) (string, reflect.Value, error) {
 	var err error
 	fieldName, err = parseJSON_field_name(json, pos)
 	if err != nil { return "", value, err }
-	if fieldName == "" { return "", value, parseJSON_syntaxError(json, pos,
-		"Did not find field name") }
-	
-	token = parseJSON_findNextToken(json, pos)
-	if token != "\"" { return fieldName, value, parseJSON_tokenError(
-		token, json, pos, "while looking for \" following a field name") }
 	
 	token = parseJSON_findNextToken(json, pos)
 	if token != ":" { return fieldName, value, parseJSON_tokenError(
-		token, json, pos, "while looking for colon following a field name") }
+		token, json, pos, "while looking for colon following a field name", ":") }
 	
-	value, err = parseJSON_value(json, pos)
+	value, err = parseJSON_value(target, fieldName, json, pos)
 	if err != nil { return fieldName, value, err }
-	if ! value.IsValid() { return fieldName, value, parseJSON_tokenError(
-		token, json, pos, "while looking for object field value") }
+	if ! value.IsValid() { return fieldName, value, parseJSON_syntaxError(
+		json, pos, "While looking for object field value", "value") }
 	
 	return fieldName, value, nil
 }
 
-func parseJSON_comma_field(json string, pos *int) ([]reflect.Value, error) {
+func parseJSON_comma_field(target interface{}, json string, pos *int) ([]string, []reflect.Value, error) {
 	
 	var token = parseJSON_findNextToken(json, pos)
-	if token == "" { return nil, nil }
+	if token == "" { return nil, nil, nil }
 	
 	if token != "," {
 		parseJSON_pushTokenBack(token, pos)
-		return nil, nil
+		return nil, nil, nil
 	}
 	
 	var err error
+	var name string
 	var value reflect.Value
+	var names []string = make([]string, 0)
 	var values []reflect.Value = make([]reflect.Value, 0)
-	_, value, err = parseJSON_field(json, pos)
-	if err != nil { return nil, err }
+	name, value, err = parseJSON_field(target, json, pos)
+	if err != nil { return nil, nil, err }
+	names = append(names, name)
 	values = append(values, value)
 	
+	var addlNames []string
 	var addlValues []reflect.Value
-	addlValues, err = parseJSON_comma_field(json, pos)
-	if err != nil { return values, err }
+	addlNames, addlValues, err = parseJSON_comma_field(target, json, pos)
+	if err != nil { return names, values, err }
 	if addlValues != nil {
+		names = append(names, addlNames...)
 		values = append(values, addlValues...)
 	}
 	
-	return values, nil
+	return names, values, nil
 }
 
+/*******************************************************************************
+ * Parse the remainder of a field name, or type name, the opening double quote
+ * of which has been read, through its closing double quote. A name, unlike a
+ * string value, may not be empty.
+ */
 func parseJSON_field_name(json string, pos *int) (string, error) {
 
-	// Find trailing double-quote.
-	var dblQuotePos = strings.Index(json[*pos:], "\"")
-	if dblQuotePos == -1 { return "", errors.New(
-		fmt.Sprintf("Terminating double quote not found for field name, after pos %d", *pos)) }
+	var startPos = *pos
+	var fieldName, err = parseJSON_string_body(json, pos)
+	if err != nil { return "", err }
+	if fieldName == "" { return "", parseJSON_syntaxErrorAt(json, startPos,
+		"Did not find field name", "field name") }
+	return fieldName, nil
+}
+
+/*******************************************************************************
+ * Parse an object that is the value of a field, and construct it by calling
+ * the target's New<typeName> method with its field values, as ReconstituteObject
+ * does for the outermost object. The name of the field is the name of the
+ * object's type, e.g., "ABC": {...}. Recursive.
+ */
+func parseJSON_nested_obj_value(target interface{}, typeName string, json string,
+	pos *int) (reflect.Value, error) {
 	
-	// ....to do: recognize escapes, etc.
+	var value reflect.Value
+	var startPos = *pos
+	var fieldNames []string
+	var argAr []reflect.Value
+	var err error
+	fieldNames, argAr, err = parseJSON_obj_value(target, json, pos)
+	if err != nil { return value, err }
+	if argAr == nil { return value, nil }
 	
-	var startPos = *pos  // beginning of field name
-	*pos += dblQuotePos  // update json pos to point to the trailing double-quote
-	var fieldName = json[startPos:*pos]
-	return fieldName, nil
+	value, err = constructObject(target, typeName, fieldNames, argAr)
+	if _, isType := err.(*BindingError); isType { return value, err }
+	if _, isType := err.(*UnknownTypeError); isType { return value, err }
+	if err != nil {
+		*pos = startPos
+		return value, parseJSON_syntaxError(json, pos, err.Error())
+	}
+	return value, nil
 }
 
-func parseJSON_value(json string, pos *int) (reflect.Value, error) {
+/*******************************************************************************
+ * Parse the value of a field. typeName is the name of the field, which is the
+ * name of the type of an object value, or of the objects in an array value.
+ */
+func parseJSON_value(target interface{}, typeName string, json string,
+	pos *int) (reflect.Value, error) {
 	
 	var value reflect.Value
 	var token = parseJSON_findNextToken(json, pos)
 	if token == "" { return value, nil }
 	
+	parseJSON_pushTokenBack(token, pos)
 	if token == "[" {
-		parseJSON_pushTokenBack(token, pos)
-		return parseJSON_array_value(json, pos)
+		return parseJSON_array_value(target, typeName, json, pos)
+	} else if token == "{" {
+		return parseJSON_nested_obj_value(target, typeName, json, pos)
 	} else {
-		parseJSON_pushTokenBack(token, pos)
 		return parseJSON_simple_value(json, pos)
 	}
 }
 
-func parseJSON_array_value(json string, pos *int) (reflect.Value, error) {
+/*******************************************************************************
+ * If the elements of the array all have the same type, return a slice of that
+ * type; otherwise - or if the array is empty - return an []interface{}.
+ * convertArg converts the slice to the type of the constructor parameter to
+ * which it is passed. The elements may be objects, of type typeName.
+ */
+func parseJSON_array_value(target interface{}, typeName string, json string,
+	pos *int) (reflect.Value, error) {
 	
 	var value reflect.Value
 	var token = parseJSON_findNextToken(json, pos)
@@ -233,38 +409,43 @@ func parseJSON_array_value(json string, pos *int) (reflect.Value, error) {
 		return value, nil
 	}
 	
+	var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
 	token = parseJSON_findNextToken(json, pos)
 	if token == "]" { // no elements in array
-		value = reflect.ValueOf(make([]interface{}, 0))
+		value = reflect.MakeSlice(reflect.SliceOf(interfaceType), 0, 0)
 		return value, nil
 	} else {
 		parseJSON_pushTokenBack(token, pos)
 	}
 	
 	var err error
-	value, err = parseJSON_value(json, pos)
+	value, err = parseJSON_value(target, typeName, json, pos)
 	if err != nil { return value, err }
-	if ! value.IsValid() { return value, nil } // no fields
+	if ! value.IsValid() { return value, parseJSON_syntaxError(json, pos,
+		"While looking for an array element", "value") }
+	var elements = []reflect.Value{ value }
 	
-	// If there are more elements in the array, they must be of the same underlying
-	// simple type as the above value.
-	var elementType = value.Type()
+	var commaValues []reflect.Value
+	commaValues, err = parseJSON_comma_value(target, typeName, json, pos)
+	if err != nil { return value, err }
+	elements = append(elements, commaValues...)
 	
-	var sliceType = reflect.SliceOf(elementType)
-	var slice = reflect.MakeSlice(sliceType, 0, 1)
-	slice = reflect.Append(slice, value)
+	token = parseJSON_findNextToken(json, pos)
+	if token != "]" { return value, parseJSON_tokenError(token, json, pos,
+		"while looking for array value", ",", "]") }
 	
-	var commaValues reflect.Value
-	commaValues, err = parseJSON_comma_value(json, pos, elementType)
-	if err != nil { return slice, err }
-	if commaValues.IsValid() {
-		slice = reflect.AppendSlice(slice, commaValues)
+	var elementType = elements[0].Type()
+	for _, element := range elements {
+		if element.Type() != elementType {
+			elementType = interfaceType
+			break
+		}
 	}
 	
-	token = parseJSON_findNextToken(json, pos)
-	if token != "]" { return slice, parseJSON_tokenError(token, json, pos,
-		"while looking for array value") }
-	
+	var slice = reflect.MakeSlice(reflect.SliceOf(elementType), 0, len(elements))
+	for _, element := range elements {
+		slice = reflect.Append(slice, element)
+	}
 	return slice, nil
 }
 
@@ -288,52 +469,65 @@ func parseJSON_simple_value(json string, pos *int) (reflect.Value, error ) {
 	if err != nil { return value, err }
 	if value.IsValid() { return value, nil }
 	
-	return value, parseJSON_syntaxError(json, pos, "While looking for simple value")
+	value, err = parseJSON_null_value(json, pos)
+	if err != nil { return value, err }
+	if value.IsValid() { return value, nil }
+	
+	return value, parseJSON_syntaxError(json, pos, "While looking for simple value", "value")
+}
+
+func parseJSON_comma_value(target interface{}, typeName string, json string,
+	pos *int) ([]reflect.Value, error) {
+	
+	var values []reflect.Value
+	for {
+		var token = parseJSON_findNextToken(json, pos)
+		if token == "" { return values, nil }
+		
+		if token != "," {
+			parseJSON_pushTokenBack(token, pos)
+			return values, nil
+		}
+		
+		var value, err = parseJSON_value(target, typeName, json, pos)
+		if err != nil { return values, err }
+		if ! value.IsValid() { return values, parseJSON_syntaxError(json, pos,
+			"While looking for an array element", "value") }
+		values = append(values, value)
+	}
 }
 
-func parseJSON_comma_value(json string, pos *int,
-	elementType reflect.Type) (reflect.Value, error) {
+var jsonNumberPattern = regexp.MustCompile(
+	`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+/*******************************************************************************
+ * An integer is returned as an int, or, if it is too large for an int, as a
+ * *big.Int; any other number is returned as a float64.
+ */
+func parseJSON_number(json string, pos *int) (reflect.Value, error) {
 	
 	var value reflect.Value
 	var token = parseJSON_findNextToken(json, pos)
 	if token == "" { return value, nil }
 	
-	if token != "," {
+	if ! jsonNumberPattern.MatchString(token) {
 		parseJSON_pushTokenBack(token, pos)
 		return value, nil
 	}
 	
-	var err error
-	value, err = parseJSON_value(json, pos)
-	if err != nil { return value, err }
-	
-	var sliceType = reflect.SliceOf(elementType)
-	var slice = reflect.MakeSlice(sliceType, 0, 1)
-	slice = reflect.Append(slice, value)
-	
-	var commaValues reflect.Value
-	commaValues, err = parseJSON_comma_value(json, pos, elementType)
-	if err != nil { return slice, err }
-	if commaValues.IsValid() {
-		slice = reflect.AppendSlice(slice, commaValues)
+	if ! strings.ContainsAny(token, ".eE") {
+		var number, err = strconv.ParseInt(token, 10, 0)
+		if err == nil { return reflect.ValueOf(int(number)), nil }
+		var bigNumber, _ = new(big.Int).SetString(token, 10)
+		return reflect.ValueOf(bigNumber), nil
 	}
 	
-	return slice, nil
-}
-
-func parseJSON_number(json string, pos *int) (reflect.Value, error) {
-	
-	var value reflect.Value
-	var token = parseJSON_findNextToken(json, pos)
-	if token == "" { return value, nil }
-	
-	var number int
-	_, err := fmt.Sscanf(token, "%d", &number)
+	var number, err = strconv.ParseFloat(token, 64)
 	if err != nil {
 		parseJSON_pushTokenBack(token, pos)
-		return value, nil
+		return value, parseJSON_syntaxError(json, pos, "Number out of range")
 	}
-	return reflect.ValueOf(number), err
+	return reflect.ValueOf(number), nil
 }
 
 func parseJSON_string_value(json string, pos *int) (reflect.Value, error) {
@@ -347,38 +541,94 @@ func parseJSON_string_value(json string, pos *int) (reflect.Value, error) {
 		return value, nil
 	}
 	
-	// Find the stirng terminator - the next unescaped double quote.
-	var posOfNextDblQuote int = -1
+	var strval, err = parseJSON_string_body(json, pos)
+	if err != nil { return value, err }
+	value = reflect.ValueOf(strval)
+	return value, nil
+}
+
+/*******************************************************************************
+ * Parse the remainder of a string, the opening double quote of which has been
+ * read: find its terminator - the next double quote that is not escaped - and
+ * return the string, unescaped, leaving pos one past the terminator.
+ */
+func parseJSON_string_body(json string, pos *int) (string, error) {
+	
 	var startPos = *pos
-	for { // until we find an unescaped double quote
-		if startPos >= len(json) { break }
-		var p = strings.Index(json[startPos:], "\"")
-		if p == -1 {  // no more double quotes in the json
+	var endPos = startPos
+	var hasEscapes = false
+	for ; endPos < len(json); endPos++ {
+		if json[endPos] == '\\' {
+			hasEscapes = true
+			endPos++  // skip the escaped character
+		} else if json[endPos] == '"' {
 			break
-		} else {  // found a double quote - now see if it is escaped
-			var decodedStr = rest.DecodeStringFromJSON(json[startPos:startPos+p+1])
-			var p2 = strings.Index(decodedStr, "\"")
-			if p2 == -1 {  // the double quote was escaped - skip past it
-				startPos += (p+1)
-				continue
-			} else {  // really found a double-quote
-				posOfNextDblQuote = startPos + p
-				break
-			}
 		}
 	}
 	
-	if posOfNextDblQuote == -1 { return value, parseJSON_syntaxError(json, pos,
-		"While looking for a string value") }
-	
-	*pos = posOfNextDblQuote
-	var strval = json[startPos: *pos]
-	*pos++  // advance one past the trailing double quote
+	if endPos >= len(json) { return "", parseJSON_syntaxErrorAt(json, len(json),
+		"While looking for the end of a string value", "\"") }
 	
+	var strval = json[startPos:endPos]
+	if hasEscapes {
+		var err error
+		strval, err = parseJSON_unescape(strval)
+		if err != nil { return "", parseJSON_syntaxError(json, pos, err.Error()) }
+	}
+	*pos = endPos+1  // advance one past the trailing double quote
+	return strval, nil
+}
+
+/*******************************************************************************
+ * Replace each escape sequence in the body of a JSON string (\", \\, \/, \b,
+ * \f, \n, \r, \t and \uXXXX) with the character that it represents.
+ */
+func parseJSON_unescape(str string) (string, error) {
 	
-	strval = rest.DecodeStringFromJSON(strval)
-	value = reflect.ValueOf(strval)
-	return value, nil
+	var buf = make([]byte, 0, len(str))
+	for i := 0; i < len(str); i++ {
+		if str[i] != '\\' {
+			buf = append(buf, str[i])
+			continue
+		}
+		i++
+		if i >= len(str) { return "", errors.New("Unterminated escape sequence") }
+		switch str[i] {
+		case '"', '\\', '/': buf = append(buf, str[i])
+		case 'b': buf = append(buf, '\b')
+		case 'f': buf = append(buf, '\f')
+		case 'n': buf = append(buf, '\n')
+		case 'r': buf = append(buf, '\r')
+		case 't': buf = append(buf, '\t')
+		case 'u':
+			var r, ok = parseJSON_hex4(str, i+1)
+			if ! ok { return "", errors.New("Invalid \\u escape sequence") }
+			i += 4
+			if utf16.IsSurrogate(r) {
+				var r2, ok2 = rune(0), false
+				if (i+2 < len(str)) && (str[i+1] == '\\') && (str[i+2] == 'u') {
+					r2, ok2 = parseJSON_hex4(str, i+3)
+				}
+				if ok2 && (utf16.DecodeRune(r, r2) != unicode.ReplacementChar) {
+					r = utf16.DecodeRune(r, r2)
+					i += 6
+				} else {
+					r = unicode.ReplacementChar
+				}
+			}
+			buf = append(buf, string(r)...)
+		default:
+			return "", errors.New(fmt.Sprintf("Invalid escape sequence \\%c", str[i]))
+		}
+	}
+	return string(buf), nil
+}
+
+func parseJSON_hex4(str string, start int) (rune, bool) {
+	if start+4 > len(str) { return 0, false }
+	var n, err = strconv.ParseUint(str[start:start+4], 16, 32)
+	if err != nil { return 0, false }
+	return rune(n), true
 }
 
 func parseJSON_bool_value(json string, pos *int) (reflect.Value, error) {
@@ -391,6 +641,29 @@ func parseJSON_bool_value(json string, pos *int) (reflect.Value, error) {
 	return value, nil
 }
 
+/*******************************************************************************
+ * null is returned as a nil interface{}, which convertArg converts to the zero
+ * value of the constructor parameter.
+ */
+func parseJSON_null_value(json string, pos *int) (reflect.Value, error) {
+	
+	var value reflect.Value
+	var token = parseJSON_findNextToken(json, pos)
+	if token != "null" {
+		parseJSON_pushTokenBack(token, pos)
+		return value, nil
+	}
+	return reflect.Zero(reflect.TypeOf((*interface{})(nil)).Elem()), nil
+}
+
+func parseJSON_isNull(value reflect.Value) bool {
+	return (value.Kind() == reflect.Interface) && value.IsNil()
+}
+
+/*******************************************************************************
+ * Parse a time value: the token time, followed by a string in the format that
+ * time.Time's MarshalJSON writes (RFC 3339).
+ */
 func parseJSON_time_value(json string, pos *int) (reflect.Value, error) {
 	
 	var value reflect.Value
@@ -400,29 +673,18 @@ func parseJSON_time_value(json string, pos *int) (reflect.Value, error) {
 		return value, nil
 	}
 	
-	var posOfFirstDblQuote = strings.Index(json[*pos:], "\"")  // relative to *pos
-	if posOfFirstDblQuote == -1 { return value, parseJSON_syntaxError(json, pos,
-		"While looking for a time value") }
-	
-	posOfFirstDblQuote += *pos
-	
-	var posOfSecondDblQuote = strings.Index(json[posOfFirstDblQuote+1:], "\"")
-	if posOfSecondDblQuote == -1 { return value, parseJSON_syntaxError(json, pos,
-		"While looking for a time value") }
-	
-	posOfSecondDblQuote += (posOfFirstDblQuote+1)
-	
-	var strval = json[posOfFirstDblQuote: posOfSecondDblQuote+1]
+	token = parseJSON_findNextToken(json, pos)
+	if token != "\"" { return value, parseJSON_tokenError(token, json, pos,
+		"While looking for a time value", "time string") }
+	var startPos = *pos - 1
+	var strval, err = parseJSON_string_body(json, pos)
+	if err != nil { return value, err }
 	
 	var t time.Time
-	var err error
-	err = t.UnmarshalJSON([]byte(strval)) // scan the time value from strval
-	if err != nil { return value, parseJSON_syntaxError(json, pos,
+	t, err = time.Parse(time.RFC3339, strval)
+	if err != nil { return value, parseJSON_syntaxErrorAt(json, startPos,
 		"While looking for a time value") }
-	value = reflect.ValueOf(t)
-
-	*pos = posOfSecondDblQuote+1
-	return value, nil
+	return reflect.ValueOf(t), nil
 }
 
 /*******************************************************************************
@@ -461,23 +723,97 @@ func parseJSON_findNextToken(json string, pos *int) (token string) {
 	return
 }
 
+/*******************************************************************************
+ * Undo parseJSON_findNextToken. The token must be the one that it last returned,
+ * which ends at *pos; the whitespace that preceded the token is not restored,
+ * and need not be, since the next call skips it.
+ */
 func parseJSON_pushTokenBack(token string, pos *int) {
 	*pos = *pos - len(token)
 }
 
-func parseJSON_syntaxError(json string, pos *int, msg string) error {
-	var err = errors.New(fmt.Sprintf("%s: at char no. %d, json=%s",
-		msg, (*pos + 1), json))
-	fmt.Println(err.Error())
-	debug.PrintStack()
-	return err
+/*******************************************************************************
+ * A syntax error in DBjson. Offset is the byte offset of the error in the JSON;
+ * Line and Column (counted in characters) start at 1. Found is the token that
+ * was found there ("" at the end of the JSON), and Expected lists the tokens,
+ * or kinds of token (e.g., "value"), that would have been valid. Snippet is the
+ * line of JSON on which the error is, abbreviated if it is long, followed by a
+ * line with a caret under the error.
+ */
+type SyntaxError struct {
+	Msg string
+	Offset int
+	Line int
+	Column int
+	Found string
+	Expected []string
+	Snippet string
+}
+
+func (syntaxError *SyntaxError) Error() string {
+	var found = "end of input"
+	if syntaxError.Found != "" { found = strconv.Quote(syntaxError.Found) }
+	var expected = make([]string, len(syntaxError.Expected))
+	for i, e := range syntaxError.Expected { expected[i] = strconv.Quote(e) }
+	var msg = fmt.Sprintf("Syntax error at line %d, column %d: %s: found %s",
+		syntaxError.Line, syntaxError.Column, syntaxError.Msg, found)
+	if len(expected) > 0 { msg = msg + ", expected " + strings.Join(expected, " or ") }
+	return msg + "\n" + syntaxError.Snippet
+}
+
+/*******************************************************************************
+ * The number of characters of context on either side of the error in a snippet.
+ */
+const syntaxErrorContext = 32
+
+func parseJSON_syntaxError(json string, pos *int, msg string, expected ...string) error {
+	return parseJSON_syntaxErrorAt(json, *pos, msg, expected...)
 }
 
-func parseJSON_tokenError(token string, json string, pos *int, msg string) error {
+func parseJSON_tokenError(token string, json string, pos *int, msg string,
+	expected ...string) error {
 	parseJSON_pushTokenBack(token, pos)
-	var err = errors.New(fmt.Sprintf("Syntax error at char no. %d: %s %s, json=%s",
-		(*pos + 1), token, msg, json))
-	fmt.Println(err.Error())
-	debug.PrintStack()
-	return err
+	return parseJSON_syntaxErrorAt(json, *pos, msg, expected...)
+}
+
+func parseJSON_syntaxErrorAt(json string, offset int, msg string, expected ...string) error {
+	
+	if offset > len(json) { offset = len(json) }
+	var tokenPos = offset
+	var found = parseJSON_findNextToken(json, &tokenPos)
+	if found != "" { offset = tokenPos - len(found) }  // skip whitespace
+	
+	var lineStart = strings.LastIndex(json[:offset], "\n") + 1
+	var lineEnd = strings.Index(json[offset:], "\n")
+	if lineEnd == -1 { lineEnd = len(json) } else { lineEnd += offset }
+	var line = strings.TrimRight(json[lineStart:lineEnd], "\r")
+	var before = []rune(json[lineStart:offset])
+	var afterStart = offset - lineStart
+	if afterStart > len(line) { afterStart = len(line) }
+	var after = []rune(line[afterStart:])
+	
+	var prefix, suffix = "", ""
+	if len(before) > syntaxErrorContext {
+		before = before[len(before)-syntaxErrorContext:]
+		prefix = "..."
+	}
+	if len(after) > syntaxErrorContext {
+		after = after[:syntaxErrorContext]
+		suffix = "..."
+	}
+	var caretIndent = []rune(prefix + string(before))
+	for i, r := range caretIndent {
+		if r != '\t' { caretIndent[i] = ' ' }
+	}
+	
+	return &SyntaxError{
+		Msg: msg,
+		Offset: offset,
+		Line: strings.Count(json[:offset], "\n") + 1,
+		Column: utf8.RuneCountInString(json[lineStart:offset]) + 1,
+		Found: found,
+		Expected: expected,
+		Snippet: "\t" + prefix + string(before) + string(after) + suffix + "\n\t" +
+			string(caretIndent) + "^",
+	}
 }
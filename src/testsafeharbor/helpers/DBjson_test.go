@@ -0,0 +1,247 @@
+package helpers
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+/*******************************************************************************
+ * Decode a stream of records of each persisted type. The throughput is
+ * reported in MB/s and records/s.
+ */
+func BenchmarkRecordDecoder(b *testing.B) {
+	var client TestPersistClient = &InMemClient{}
+	var records = randomRecords(client, rand.New(rand.NewSource(1)), 1000)
+	var stream = strings.Join(records, "\n")
+	b.SetBytes(int64(len(stream)))
+	b.ResetTimer()
+
+	var start = time.Now()
+	for i := 0; i < b.N; i++ {
+		var decoder = NewRecordDecoder(client, strings.NewReader(stream))
+		for {
+			var _, _, err = decoder.Decode()
+			if err == io.EOF { break }
+			if err != nil { b.Fatal(err) }
+		}
+	}
+	b.ReportMetric(float64(b.N * len(records)) / time.Since(start).Seconds(), "records/s")
+}
+
+/*******************************************************************************
+ * Reconstitute the same records one at a time, from strings, for comparison
+ * with BenchmarkRecordDecoder.
+ */
+func BenchmarkReconstituteObject(b *testing.B) {
+	var client TestPersistClient = &InMemClient{}
+	var records = randomRecords(client, rand.New(rand.NewSource(1)), 1000)
+	b.SetBytes(int64(len(strings.Join(records, "\n"))))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, record := range records {
+			var _, _, err = ReconstituteObject(client, record)
+			if err != nil { b.Fatal(err) }
+		}
+	}
+}
+
+/*******************************************************************************
+ * Parse arbitrary input, which must not panic or hang; any SyntaxError must be
+ * within the input. The seeds are the records of each persisted type, and
+ * fragments of them that have tripped the parser.
+ */
+func FuzzParseJSON(f *testing.F) {
+	for _, seed := range fuzzSeeds(f) { f.Add(seed) }
+	f.Fuzz(func(t *testing.T, input string) {
+		var _, err = parseJSON(input)
+		checkSyntaxError(t, input, err)
+	})
+}
+
+/*******************************************************************************
+ * Reconstitute arbitrary input. If an object is constructed, it must serialize
+ * to JSON that reconstitutes to an object that serializes to the same JSON.
+ */
+func FuzzReconstituteObject(f *testing.F) {
+	for _, seed := range fuzzSeeds(f) { f.Add(seed) }
+	f.Fuzz(func(t *testing.T, input string) {
+		var client TestPersistClient = &InMemClient{}
+		var typeName, obj, err = ReconstituteObject(client, input)
+		checkSyntaxError(t, input, err)
+		if err != nil { return }
+		var persistable, isType = obj.(Persistable)
+		if ! isType { t.Fatalf("%s was reconstituted as a %T, which is not Persistable", typeName, obj) }
+
+		var expected = persistable.toJSON()
+		_, obj, err = ReconstituteObject(client, expected)
+		if err != nil { t.Fatalf("%s\nreconstituted from %q, but not from its own JSON %q", err, input, expected) }
+		if actual := obj.(Persistable).toJSON(); actual != expected {
+			t.Fatalf("JSON of %q is %q, but after another round trip it is %q", input, expected, actual)
+		}
+	})
+}
+
+/*******************************************************************************
+ * Differential test against encoding/json: any input that encoding/json accepts
+ * as an object whose fields are plain values - strings, numbers, booleans, null,
+ * and arrays of them - must parse to the same field names and values. (DBjson
+ * objects nested in fields are typed, so they are not comparable; DBjson does
+ * not accept empty field names; and it accepts time values, which encoding/json
+ * does not.)
+ */
+func FuzzParseJSONDifferential(f *testing.F) {
+	for _, seed := range []string{
+		`{}`,
+		`{"a": 1, "bs": "x", "car": ["y", "z"], "db": true}`,
+		` { "n" : null , "e" : [ ] } `,
+		`{"f": -1.5e3, "g": 0.25, "h": 1E2, "i": -0}`,
+		`{"big": 123456789012345678901234567890, "neg": -9223372036854775808}`,
+		`{"s": "quote \" backslash \\ slash \/ \b\f\n\r\t \u00e9 \ud83d\ude00"}`,
+		`{"mixed": [1, "two", 3.5, false, null, [4]]}`,
+		`{"key with spaces": "value, with: punctuation {}[]"}`,
+		`{"escaped \"key\"": 1, "\u0041": 2}`,
+		`{"unicode": "héllo wörld ✓"}`,
+		"{\n\t\"a\":\r\n[1,\n2]\n}",
+	} { f.Add(seed) }
+	f.Fuzz(func(t *testing.T, input string) {
+		var expectedNames, expectedValues, comparable = plainJSONFields(input)
+		if ! comparable { return }
+
+		var pos = 0
+		var names, values, err = parseJSON_obj_value(nil, input, &pos)
+		if err != nil { t.Fatalf("encoding/json accepts %q, but parseJSON does not: %s", input, err) }
+		if values == nil { t.Fatalf("encoding/json accepts %q, but parseJSON finds no object", input) }
+		if ! reflect.DeepEqual(names, expectedNames) && (len(names) + len(expectedNames) > 0) {
+			t.Fatalf("Field names of %q are %q, expected %q", input, names, expectedNames)
+		}
+		for i, value := range values {
+			if ! plainJSONEqual(value, expectedValues[i]) {
+				t.Fatalf("Field %q of %q is %#v, expected %#v", names[i], input,
+					value.Interface(), expectedValues[i])
+			}
+		}
+	})
+}
+
+/*******************************************************************************
+ * The JSON of randomly generated objects of each persisted type, and each
+ * prefix of one of them, which exercise the parser's handling of the end of
+ * the input.
+ */
+func fuzzSeeds(f *testing.F) []string {
+	var seeds = randomRecords(&InMemClient{}, rand.New(rand.NewSource(1)), 8)
+	var record = seeds[0]
+	for i := 0; i < len(record); i += 7 { seeds = append(seeds, record[:i]) }
+	return append(seeds,
+		`"ABC": {"a": 1 "bs": "x"}`,
+		`"ABC" : { "a" : 1 , "bs" : "x" , "car" : [ "y" ] , "db" : true }`,
+		`"JKL": {"ABC": [], "created": time "2017-01-01T00:00:00Z"}`,
+		`"GHI": {"score": 1e400}`,
+		`"DEF": {"ABC": {"a": 1, "bs": "\"}", "car": null, "db": false}}`)
+}
+
+func checkSyntaxError(t *testing.T, input string, err error) {
+	var syntaxError, isType = err.(*SyntaxError)
+	if ! isType { return }
+	if (syntaxError.Offset < 0) || (syntaxError.Offset > len(input)) ||
+		(syntaxError.Line < 1) || (syntaxError.Column < 1) {
+		t.Fatalf("Error in %q is at offset %d, line %d, column %d: %s", input,
+			syntaxError.Offset, syntaxError.Line, syntaxError.Column, syntaxError)
+	}
+}
+
+/*******************************************************************************
+ * Return the field names and values of the object that the input defines, as
+ * encoding/json decodes them, if they can be compared with those of parseJSON.
+ */
+func plainJSONFields(input string) ([]string, []interface{}, bool) {
+	if ! utf8.ValidString(input) { return nil, nil, false }  // encoding/json replaces invalid bytes
+	if ! json.Valid([]byte(input)) { return nil, nil, false }
+	var decoder = json.NewDecoder(strings.NewReader(input))
+	decoder.UseNumber()
+	var token, _ = decoder.Token()
+	if token != json.Delim('{') { return nil, nil, false }
+
+	var names []string
+	var values []interface{}
+	for decoder.More() {
+		token, _ = decoder.Token()
+		var value interface{}
+		if decoder.Decode(&value) != nil { return nil, nil, false }
+		if ! isPlainJSON(value) { return nil, nil, false }
+		if token == "" { return nil, nil, false }  // DBjson field names are type names
+		names = append(names, token.(string))
+		values = append(values, value)
+	}
+	return names, values, true
+}
+
+func isPlainJSON(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}: return false
+	case json.Number:
+		var _, err = strconv.ParseFloat(string(v), 64)
+		return err == nil  // parseJSON reports numbers that overflow a float64
+	case []interface{}:
+		for _, element := range v { if ! isPlainJSON(element) { return false } }
+	}
+	return true
+}
+
+func plainJSONEqual(value reflect.Value, expected interface{}) bool {
+	if value.Kind() == reflect.Interface {
+		if value.IsNil() { return expected == nil }
+		value = value.Elem()
+	}
+	switch e := expected.(type) {
+	case nil: return false
+	case bool: return (value.Kind() == reflect.Bool) && (value.Bool() == e)
+	case string: return (value.Kind() == reflect.String) && (value.String() == e)
+	case json.Number:
+		var expectedInt, isInt = new(big.Int).SetString(e.String(), 10)
+		switch n := value.Interface().(type) {
+		case int: return isInt && (expectedInt.Cmp(big.NewInt(int64(n))) == 0)
+		case *big.Int: return isInt && (expectedInt.Cmp(n) == 0)
+		case float64:
+			var expectedFloat, _ = strconv.ParseFloat(e.String(), 64)
+			return ! isInt && (expectedFloat == n)
+		}
+		return false
+	case []interface{}:
+		if (value.Kind() != reflect.Slice) || (value.Len() != len(e)) { return false }
+		for i, element := range e {
+			if ! plainJSONEqual(value.Index(i), element) { return false }
+		}
+		return true
+	}
+	return false
+}
+
+/*******************************************************************************
+ * A struct that is not a JSONObject is written with its exported fields only.
+ */
+func TestToJSONExportedFields(t *testing.T) {
+	type exported struct {
+		Name string
+		hidden int
+		Count int `dbjson:"count"`
+		Skipped string `dbjson:"-"`
+		Nested ABC `dbjson:"ABC"`
+		Missing ABC
+	}
+	var object = exported{ "x", 1, 2, "y", &InMemABC{ 3, "z", nil, true }, nil }
+	var json, err = ToJSON("XYZ", object)
+	if err != nil { t.Fatal(err) }
+	var expected = `"XYZ": {"Name": "x", "count": 2, ` +
+		`"ABC": {"a": 3, "bs": "z", "car": null, "db": true}, "Missing": null}`
+	if json != expected { t.Errorf("JSON is %s, expected %s", json, expected) }
+}
@@ -0,0 +1,155 @@
+/*******************************************************************************
+ * Binding of the fields of a DBjson object to the parameters of the New<Type>
+ * method that constructs it, by name. Go does not record the names of a
+ * method's parameters, so they are registered, for each type, with
+ * RegisterParams or RegisterType (see DBjsontypes.go). A type whose parameters
+ * have not been registered is bound by position.
+ */
+
+package helpers
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"errors"
+)
+
+/*******************************************************************************
+ * A parameter of a New<Type> method. If Optional, the field may be omitted from
+ * the JSON, in which case Default is passed - or, if Default is nil, the zero
+ * value of the parameter type.
+ */
+type Param struct {
+	Name string
+	Optional bool
+	Default interface{}
+}
+
+/*******************************************************************************
+ * Return a required parameter.
+ */
+func RequiredParam(name string) Param {
+	return Param{ Name: name }
+}
+
+/*******************************************************************************
+ * Return an optional parameter, with the value to pass if its field is absent.
+ */
+func OptionalParam(name string, defaultValue interface{}) Param {
+	return Param{ Name: name, Optional: true, Default: defaultValue }
+}
+
+/*******************************************************************************
+ * A field whose value cannot be passed as the parameter of the same name.
+ */
+type FieldMismatch struct {
+	Field string
+	ParamType reflect.Type
+	Reason string
+}
+
+/*******************************************************************************
+ * The fields of a JSON object do not match the parameters of the New<Type>
+ * method that constructs it.
+ */
+type BindingError struct {
+	TypeName string
+	Missing []string  // required parameters for which there is no field
+	Extra []string  // fields for which there is no parameter
+	Duplicate []string  // fields that appear more than once
+	Mismatched []FieldMismatch
+}
+
+func (bindingError *BindingError) Error() string {
+	var problems = []string{}
+	if len(bindingError.Missing) > 0 {
+		problems = append(problems, "missing fields " + strings.Join(bindingError.Missing, ", "))
+	}
+	if len(bindingError.Extra) > 0 {
+		problems = append(problems, "unknown fields " + strings.Join(bindingError.Extra, ", "))
+	}
+	if len(bindingError.Duplicate) > 0 {
+		problems = append(problems, "duplicate fields " + strings.Join(bindingError.Duplicate, ", "))
+	}
+	for _, mismatch := range bindingError.Mismatched {
+		problems = append(problems, fmt.Sprintf("field %s: %s",
+			mismatch.Field, mismatch.Reason))
+	}
+	return fmt.Sprintf("Cannot construct a %s: %s", bindingError.TypeName,
+		strings.Join(problems, "; "))
+}
+
+func (bindingError *BindingError) hasProblems() bool {
+	return (len(bindingError.Missing) > 0) || (len(bindingError.Extra) > 0) ||
+		(len(bindingError.Duplicate) > 0) || (len(bindingError.Mismatched) > 0)
+}
+
+/*******************************************************************************
+ * Return the arguments with which to call a New<typeName> method of the
+ * specified type: for each parameter, the value of the field of the same name,
+ * converted to the parameter type. A parameter named for a type may instead be
+ * passed the field named for one of its subtypes (see IsA) - e.g., the ABC of a
+ * DEF may be written as "DEF": {...} - since ToJSON writes a nested object
+ * under the name of its own type. Every problem found is reported in a
+ * *BindingError.
+ */
+func bindArgs(typeName string, params []Param, methodType reflect.Type,
+	fieldNames []string, fieldValues []reflect.Value) ([]reflect.Value, error) {
+
+	if len(params) != methodType.NumIn() { return nil, errors.New(fmt.Sprintf(
+		"%d parameters are registered for %s, but New%s takes %d",
+		len(params), typeName, typeName, methodType.NumIn())) }
+
+	var bindingError = &BindingError{ TypeName: typeName }
+	var fields = make(map[string]reflect.Value)
+	for i, name := range fieldNames {
+		if _, found := fields[name]; found {
+			bindingError.Duplicate = append(bindingError.Duplicate, name)
+			continue
+		}
+		fields[name] = fieldValues[i]
+	}
+
+	var args = make([]reflect.Value, len(params))
+	var paramNames = make(map[string]bool)
+	for _, param := range params { paramNames[param.Name] = true }
+	var subtypeFields = make(map[string]bool)  // bound to the parameter of a supertype
+	for i, param := range params {
+		var paramType = methodType.In(i)
+		var fieldValue, found = fields[param.Name]
+		if ! found {
+			for _, name := range fieldNames {
+				if paramNames[name] || subtypeFields[name] || ! IsA(name, param.Name) { continue }
+				fieldValue, found = fields[name], true
+				subtypeFields[name] = true
+				break
+			}
+		}
+		if ! found {
+			if ! param.Optional {
+				bindingError.Missing = append(bindingError.Missing, param.Name)
+				continue
+			}
+			if param.Default == nil {
+				args[i] = reflect.Zero(paramType)
+				continue
+			}
+			fieldValue = reflect.ValueOf(param.Default)
+		}
+		var arg, err = convertArg(fieldValue, paramType)
+		if err != nil {
+			bindingError.Mismatched = append(bindingError.Mismatched,
+				FieldMismatch{ param.Name, paramType, err.Error() })
+			continue
+		}
+		args[i] = arg
+	}
+
+	for _, name := range fieldNames {
+		if ! paramNames[name] && ! subtypeFields[name] { bindingError.Extra = append(bindingError.Extra, name) }
+	}
+
+	if bindingError.hasProblems() { return nil, bindingError }
+	return args, nil
+}
@@ -0,0 +1,182 @@
+/*******************************************************************************
+ * A RecordDecoder reconstitutes a stream of DBjson objects - e.g., a dump of a
+ * Redis database - from an io.Reader. The stream is a sequence of records, each
+ * of the form
+ *	"type-name": {...}
+ * separated by whitespace and, optionally, commas. The RecordDecoder does not
+ * parse the stream: it splits it into records - reading each one whole into
+ * memory, and tracking only strings and braces to find where it ends - and
+ * parses each record with ReconstituteObject. The stream as a whole is never in
+ * memory, but the largest record is, and each record is read twice: once to
+ * split it, and once to parse it. The positions in a SyntaxError are those in
+ * the stream.
+ */
+
+package helpers
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+type RecordDecoder struct {
+	target interface{}
+	reader *bufio.Reader
+	offset int  // of the next byte to be read
+	line int  // of the next byte to be read
+	column int  // of the next byte to be read
+	record []byte
+}
+
+/*******************************************************************************
+ * Return a RecordDecoder that reads from reader, and constructs objects with the
+ * registered factories or the target's New<Type> methods, as ReconstituteObject
+ * does.
+ */
+func NewRecordDecoder(target interface{}, reader io.Reader) *RecordDecoder {
+	return &RecordDecoder{
+		target: target,
+		reader: bufio.NewReaderSize(reader, 64 * 1024),
+		line: 1,
+		column: 1,
+	}
+}
+
+/*******************************************************************************
+ * Read the next record, and return its type name and the object. At the end of
+ * the stream, return io.EOF. If a record cannot be delimited - e.g., its braces
+ * do not balance - the rest of the stream cannot be decoded; any other error
+ * (e.g., a BindingError) applies only to the record.
+ */
+func (decoder *RecordDecoder) Decode() (string, interface{}, error) {
+
+	var c byte
+	var err error
+	for { // skip separators
+		c, err = decoder.reader.ReadByte()
+		if err != nil { return "", nil, err }
+		if strings.IndexByte(" \t\r\n,", c) == -1 { break }
+		decoder.advance(c)
+	}
+	decoder.reader.UnreadByte()
+
+	var startOffset, startLine, startColumn = decoder.offset, decoder.line, decoder.column
+	err = decoder.readRecord()
+	var json = string(decoder.record)
+	if err != nil { return "", nil, decoder.streamError(err,
+		startOffset, startLine, startColumn) }
+
+	var typeName string
+	var object interface{}
+	typeName, object, err = ReconstituteObject(decoder.target, json)
+	if err != nil { return typeName, nil, decoder.streamError(err,
+		startOffset, startLine, startColumn) }
+	return typeName, object, nil
+}
+
+/*******************************************************************************
+ * Read a record into decoder.record: a string (the type name), a colon, and
+ * an object, which ends at the brace that balances its first brace.
+ */
+func (decoder *RecordDecoder) readRecord() error {
+
+	decoder.record = decoder.record[:0]
+	var expect = func(expected byte, msg string) error {
+		for {
+			var c, err = decoder.readByte()
+			if err != nil { return decoder.recordError(err, msg, string(expected)) }
+			if strings.IndexByte(" \t\r\n", c) != -1 { continue }
+			if c != expected { return decoder.recordError(nil, msg, string(expected)) }
+			return nil
+		}
+	}
+
+	var err = expect('"', "While looking for a type name")
+	if err != nil { return err }
+	err = decoder.readString()
+	if err != nil { return err }
+	err = expect(':', "While looking for colon following the type name")
+	if err != nil { return err }
+	err = expect('{', "While looking for the fields of an object")
+	if err != nil { return err }
+
+	var depth = 1
+	for depth > 0 {
+		var c, err = decoder.readByte()
+		if err != nil { return decoder.recordError(err, "While looking for object terminator", "}") }
+		switch c {
+		case '"':
+			err = decoder.readString()
+			if err != nil { return err }
+		case '{': depth++
+		case '}': depth--
+		}
+	}
+	return nil
+}
+
+/*******************************************************************************
+ * Read the remainder of a string, up to and including its closing quote.
+ */
+func (decoder *RecordDecoder) readString() error {
+	for {
+		var c, err = decoder.readByte()
+		if err != nil { return decoder.recordError(err,
+			"While looking for the end of a string value", "\"") }
+		switch c {
+		case '\\':
+			_, err = decoder.readByte()
+			if err != nil { return decoder.recordError(err,
+				"While looking for the end of a string value", "\"") }
+		case '"':
+			return nil
+		}
+	}
+}
+
+func (decoder *RecordDecoder) readByte() (byte, error) {
+	var c, err = decoder.reader.ReadByte()
+	if err != nil { return c, err }
+	decoder.record = append(decoder.record, c)
+	decoder.advance(c)
+	return c, nil
+}
+
+func (decoder *RecordDecoder) advance(c byte) {
+	decoder.offset++
+	if c == '\n' {
+		decoder.line++
+		decoder.column = 1
+	} else if (c & 0xC0) != 0x80 {  // not a continuation byte of a UTF-8 character
+		decoder.column++
+	}
+}
+
+/*******************************************************************************
+ * Return a SyntaxError, relative to the record read so far (streamError makes
+ * it relative to the stream): at the last byte read, which was not what was
+ * expected, or, if readErr is io.EOF, at the end. Any other readErr is returned.
+ */
+func (decoder *RecordDecoder) recordError(readErr error, msg string, expected ...string) error {
+	if (readErr != nil) && (readErr != io.EOF) { return readErr }
+	var json = string(decoder.record)
+	var offset = len(json)
+	if readErr == nil { offset-- }
+	return parseJSON_syntaxErrorAt(json, offset, msg, expected...)
+}
+
+/*******************************************************************************
+ * If err is a SyntaxError in the record that starts at the specified position
+ * in the stream, convert its position to one in the stream.
+ */
+func (decoder *RecordDecoder) streamError(err error,
+	startOffset, startLine, startColumn int) error {
+
+	var syntaxError, isType = err.(*SyntaxError)
+	if ! isType { return err }
+	if syntaxError.Line == 1 { syntaxError.Column += (startColumn - 1) }
+	syntaxError.Offset += startOffset
+	syntaxError.Line += (startLine - 1)
+	return syntaxError
+}
@@ -0,0 +1,174 @@
+/*******************************************************************************
+ * The registry of persisted types. Each type is registered, by name, with the
+ * factory that constructs it, the names of the factory's parameters (i.e., of
+ * its fields - see DBjsonbind.go), the Go interface that its objects implement,
+ * and its parent types, i.e., the types that it is also an instance of: a DEF,
+ * which embeds an ABC, is an ABC. ReconstituteAs uses the parent types to
+ * accept an object of any subtype of the requested type.
+ *
+ * A type that is registered without a factory is constructed by the New<Type>
+ * method of the target that is passed to ReconstituteObject; a type that is
+ * not registered at all, only if the target has such a method.
+ */
+
+package helpers
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type TypeSpec struct {
+	Name string
+	Factory interface{}  // a func that returns the object; nil to use New<Name> on the target
+	Interface interface{}  // a nil pointer to the interface, e.g., (*ABC)(nil); may be nil
+	Params []Param  // of Factory, in order; nil to bind fields by position
+	Parents []string
+}
+
+type typeInfo struct {
+	spec TypeSpec
+	factory reflect.Value
+	iface reflect.Type
+}
+
+/*******************************************************************************
+ * The JSON names a type that is not registered, and that the target cannot
+ * construct.
+ */
+type UnknownTypeError struct {
+	TypeName string
+	KnownTypes []string
+}
+
+func (unknownTypeError *UnknownTypeError) Error() string {
+	return fmt.Sprintf("Unknown type %s; the known types are %s", unknownTypeError.TypeName,
+		strings.Join(unknownTypeError.KnownTypes, ", "))
+}
+
+var typeRegistry = make(map[string]*typeInfo)
+var typeRegistryLock sync.RWMutex
+
+/*******************************************************************************
+ * Register a persisted type, replacing any earlier registration of its name.
+ * Panics if the spec is inconsistent: types are registered when the program
+ * starts, so that is a programming error.
+ */
+func RegisterType(spec TypeSpec) {
+	var info = &typeInfo{ spec: spec }
+	if spec.Factory != nil {
+		info.factory = reflect.ValueOf(spec.Factory)
+		if (info.factory.Kind() != reflect.Func) || (info.factory.Type().NumOut() == 0) {
+			panic("Factory of type " + spec.Name + " is not a func that returns a value")
+		}
+		if (spec.Params != nil) && (len(spec.Params) != info.factory.Type().NumIn()) {
+			panic(fmt.Sprintf("%d parameters are registered for type %s, but its factory takes %d",
+				len(spec.Params), spec.Name, info.factory.Type().NumIn()))
+		}
+	}
+	if spec.Interface != nil {
+		info.iface = reflect.TypeOf(spec.Interface).Elem()
+		if info.factory.IsValid() &&
+			! info.factory.Type().Out(0).Implements(info.iface) {
+			panic("Factory of type " + spec.Name + " does not return a " + info.iface.String())
+		}
+	}
+	typeRegistryLock.Lock()
+	defer typeRegistryLock.Unlock()
+	typeRegistry[spec.Name] = info
+}
+
+/*******************************************************************************
+ * Register the parameters of the New<typeName> method, in order, so that the
+ * fields of a typeName object are bound to them by name. The type is otherwise
+ * as registered, if it is.
+ */
+func RegisterParams(typeName string, params ...Param) {
+	var spec = TypeSpec{ Name: typeName }
+	if info, found := lookupType(typeName); found { spec = info.spec }
+	spec.Params = params
+	RegisterType(spec)
+}
+
+/*******************************************************************************
+ * Return the names of the registered types, in order.
+ */
+func KnownTypes() []string {
+	typeRegistryLock.RLock()
+	defer typeRegistryLock.RUnlock()
+	var names = make([]string, 0, len(typeRegistry))
+	for name, _ := range typeRegistry { names = append(names, name) }
+	sort.Strings(names)
+	return names
+}
+
+/*******************************************************************************
+ * Return true if typeName is ancestorName, or one of its parent types is,
+ * recursively.
+ */
+func IsA(typeName, ancestorName string) bool {
+	return isA(typeName, ancestorName, make(map[string]bool))
+}
+
+func isA(typeName, ancestorName string, visited map[string]bool) bool {
+	if typeName == ancestorName { return true }
+	if visited[typeName] { return false }  // a cycle of parents
+	visited[typeName] = true
+	var info, found = lookupType(typeName)
+	if ! found { return false }
+	for _, parent := range info.spec.Parents {
+		if isA(parent, ancestorName, visited) { return true }
+	}
+	return false
+}
+
+/*******************************************************************************
+ * Reconstitute the object defined by the JSON, as ReconstituteObject does, if
+ * it is of type typeName or of one of its subtypes (see IsA); otherwise,
+ * return an error. If typeName is registered with an interface, the object
+ * implements it.
+ */
+func ReconstituteAs(target interface{}, json string, typeName string) (string, interface{}, error) {
+	var actualTypeName, _, err = retrieveTypeName(json)
+	if err != nil { return actualTypeName, nil, err }
+	if ! IsA(actualTypeName, typeName) { return actualTypeName, nil, fmt.Errorf(
+		"Expected a %s, but found a %s", typeName, actualTypeName) }
+
+	var obj interface{}
+	actualTypeName, obj, err = ReconstituteObject(target, json)
+	if err != nil { return actualTypeName, nil, err }
+	if info, found := lookupType(typeName); found && (info.iface != nil) &&
+		! reflect.TypeOf(obj).Implements(info.iface) {
+		return actualTypeName, nil, fmt.Errorf("A %s was constructed as a %T, which is not a %s",
+			actualTypeName, obj, info.iface.String())
+	}
+	return actualTypeName, obj, nil
+}
+
+func lookupType(typeName string) (*typeInfo, bool) {
+	typeRegistryLock.RLock()
+	defer typeRegistryLock.RUnlock()
+	var info, found = typeRegistry[typeName]
+	return info, found
+}
+
+/*******************************************************************************
+ * Return the function that constructs objects of the type, and its registered
+ * parameters, if any. An *UnknownTypeError is returned if there is none.
+ */
+func findFactory(target interface{}, typeName string) (reflect.Value, []Param, error) {
+	var info, registered = lookupType(typeName)
+	var params []Param
+	if registered {
+		params = info.spec.Params
+		if info.factory.IsValid() { return info.factory, params, nil }
+	}
+	if target != nil {
+		var method = reflect.ValueOf(target).MethodByName("New" + typeName)
+		if method.IsValid() { return method, params, nil }
+	}
+	return reflect.Value{}, nil, &UnknownTypeError{ typeName, KnownTypes() }
+}
@@ -0,0 +1,259 @@
+/*******************************************************************************
+ * Serialization of objects in the JSON dialect that ReconstituteObject reads:
+ *	"type-name": {"field-name": value, ...}
+ * The fields of an object are those that it returns from JSONFields, if it is a
+ * JSONObject, and otherwise the exported fields of its struct, in order, named
+ * by their dbjson tag or, if none, by the name of the struct field. A nested
+ * object is written as the value of a field whose name is the object's type
+ * name - e.g., the embedded ABC of an InMemDEF - as is an array of objects. If the field holds an object of a subtype, such as a DEF in
+ * the ABC of a DEF, it is written under the subtype's name instead, so that the
+ * object is reconstituted as one of the same type. A time.Time is written as a
+ * time literal (time "..."), and a nil pointer, interface or slice as null. A
+ * field tagged `dbjson:"-"` is not written.
+ */
+
+package helpers
+
+import (
+	"fmt"
+	"bytes"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+/*******************************************************************************
+ * Return the JSON for the specified object, of the specified type. object is a
+ * struct, or a pointer to one.
+ */
+func ToJSON(typeName string, object interface{}) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteString(quoteJSONString(typeName))
+	buf.WriteString(": ")
+	var err = writeJSON_obj_value(&buf, reflect.ValueOf(object))
+	if err != nil { return "", errors.New(fmt.Sprintf("Cannot serialize a %s: %s",
+		typeName, err.Error())) }
+	return buf.String(), nil
+}
+
+/*******************************************************************************
+ * A type that keeps its state in unexported fields, which reflect does not
+ * allow to be read, supplies the fields to write by implementing JSONObject.
+ */
+type JSONObject interface {
+	JSONFields() []JSONField
+}
+
+type JSONField struct {
+	Name string
+	Value interface{}
+}
+
+func writeJSON_obj_value(buf *bytes.Buffer, value reflect.Value) error {
+
+	var fields, err = objectFields(value)
+	if err != nil { return err }
+	buf.WriteString("{")
+	for i, field := range fields {
+		var fieldValue = reflect.ValueOf(field.Value)
+		var fieldName string
+		fieldName, err = dynamicTypeName(field.Name, fieldValue)
+		if err != nil { return errors.New(fieldName + ": " + err.Error()) }
+		if i > 0 { buf.WriteString(", ") }
+		buf.WriteString(quoteJSONString(fieldName))
+		buf.WriteString(": ")
+		err = writeJSON_value(buf, fieldValue)
+		if err != nil { return errors.New(fieldName + ": " + err.Error()) }
+	}
+	buf.WriteString("}")
+	return nil
+}
+
+/*******************************************************************************
+ * Return the fields of the specified object: those it supplies, if it - or what
+ * it points to - is a JSONObject, and otherwise the exported fields of its
+ * struct, except those tagged `dbjson:"-"`.
+ */
+func objectFields(value reflect.Value) ([]JSONField, error) {
+
+	if ! value.IsValid() { return nil, errors.New("nil is not an object") }
+	for {
+		switch value.Kind() {
+		case reflect.Ptr, reflect.Interface:
+			if value.IsNil() { return nil, errors.New(
+				"A nil " + value.Type().String() + " is not an object") }
+		}
+		if object, isObject := value.Interface().(JSONObject); isObject {
+			return object.JSONFields(), nil
+		}
+		if (value.Kind() != reflect.Ptr) && (value.Kind() != reflect.Interface) { break }
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct { return nil, errors.New(
+		"A " + value.Type().String() + " is not an object") }
+	if value.CanAddr() {  // e.g., a struct reached through a pointer
+		if object, isObject := value.Addr().Interface().(JSONObject); isObject {
+			return object.JSONFields(), nil
+		}
+	}
+
+	var fields = []JSONField{}
+	for i := 0; i < value.NumField(); i++ {
+		var structField = value.Type().Field(i)
+		if structField.PkgPath != "" { continue }  // unexported
+		var fieldName = structField.Tag.Get("dbjson")
+		if fieldName == "-" { continue }
+		if fieldName == "" { fieldName = structField.Name }
+		fields = append(fields, JSONField{ fieldName, value.Field(i).Interface() })
+	}
+	return fields, nil
+}
+
+func writeJSON_value(buf *bytes.Buffer, value reflect.Value) error {
+
+	if ! value.IsValid() {  // a nil interface{}
+		buf.WriteString("null")
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		if value.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+	}
+
+	switch v := value.Interface().(type) {
+	case time.Time:
+		var bytes, err = v.MarshalJSON()
+		if err != nil { return err }
+		buf.WriteString("time ")
+		buf.Write(bytes)
+		return nil
+	case *big.Int:
+		buf.WriteString(v.String())
+		return nil
+	case big.Int:
+		buf.WriteString(v.String())
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return writeJSON_value(buf, value.Elem())
+	case reflect.Struct:
+		return writeJSON_obj_value(buf, value)
+	case reflect.String:
+		buf.WriteString(quoteJSONString(value.String()))
+	case reflect.Bool:
+		buf.WriteString(BoolToString(value.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString(strconv.FormatInt(value.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buf.WriteString(strconv.FormatUint(value.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		var f = value.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) { return errors.New(fmt.Sprintf(
+			"%v cannot be represented in JSON", f)) }
+		buf.WriteString(strconv.FormatFloat(f, 'g', -1, value.Type().Bits()))
+	case reflect.Slice, reflect.Array:
+		buf.WriteString("[")
+		for i := 0; i < value.Len(); i++ {
+			if i > 0 { buf.WriteString(", ") }
+			var err = writeJSON_value(buf, value.Index(i))
+			if err != nil { return errors.New(fmt.Sprintf("[%d]: %s", i, err.Error())) }
+		}
+		buf.WriteString("]")
+	default:
+		return errors.New("Values of type " + value.Type().String() + " are not supported")
+	}
+	return nil
+}
+
+/*******************************************************************************
+ * Return the name under which to write a field whose name is that of a
+ * registered type: the name of the most specific subtype (see IsA) whose
+ * registered interface the field's object implements, or the field name if
+ * there is none. The objects of an array must all be of the same type, since
+ * the array is written under one name. A field whose name is not that of a
+ * registered type is written under its own name.
+ */
+func dynamicTypeName(fieldName string, value reflect.Value) (string, error) {
+	if _, found := lookupType(fieldName); ! found { return fieldName, nil }
+
+	var objects = []reflect.Value{ value }
+	if (value.Kind() == reflect.Slice) || (value.Kind() == reflect.Array) {
+		objects = make([]reflect.Value, value.Len())
+		for i, _ := range objects { objects[i] = value.Index(i) }
+	}
+	var typeName = ""
+	for _, object := range objects {
+		if (object.Kind() == reflect.Interface) && ! object.IsNil() { object = object.Elem() }
+		switch object.Kind() {
+		case reflect.Invalid: continue  // null, of no type
+		case reflect.Ptr, reflect.Interface:
+			if object.IsNil() { continue }
+		}
+		var objectTypeName, err = registeredTypeName(fieldName, object.Type())
+		if err != nil { return fieldName, err }
+		if typeName == "" {
+			typeName = objectTypeName
+		} else if objectTypeName != typeName {
+			return fieldName, errors.New(fmt.Sprintf(
+				"The objects are of different types, %s and %s", typeName, objectTypeName))
+		}
+	}
+	if typeName == "" { return fieldName, nil }
+	return typeName, nil
+}
+
+/*******************************************************************************
+ * Return the name of the most specific type that is typeName or one of its
+ * subtypes, and whose registered interface objectType implements.
+ */
+func registeredTypeName(typeName string, objectType reflect.Type) (string, error) {
+	var best = typeName
+	for _, name := range KnownTypes() {
+		if ! IsA(name, typeName) { continue }
+		var info, _ = lookupType(name)
+		if (info.iface == nil) || ! objectType.Implements(info.iface) { continue }
+		if IsA(name, best) {
+			best = name
+		} else if ! IsA(best, name) {
+			return typeName, errors.New(fmt.Sprintf(
+				"A %s is both a %s and a %s", objectType.String(), best, name))
+		}
+	}
+	return best, nil
+}
+
+/*******************************************************************************
+ * Return a JSON string literal for the specified string, escaping double quotes,
+ * backslashes and control characters. Invalid UTF-8 is written as U+FFFD,
+ * unescaped, like the replacement character itself, so that the literal read
+ * back and written again is the same.
+ */
+func quoteJSONString(s string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		var r, size = utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '"': buf.WriteString("\\\"")
+		case r == '\\': buf.WriteString("\\\\")
+		case r == '\n': buf.WriteString("\\n")
+		case r == '\r': buf.WriteString("\\r")
+		case r == '\t': buf.WriteString("\\t")
+		case r < 0x20: buf.WriteString(fmt.Sprintf("\\u%04x", r))
+		case (r == utf8.RuneError) && (size == 1): buf.WriteRune(utf8.RuneError)
+		default: buf.WriteString(s[i:i+size])
+		}
+		i += size
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
@@ -0,0 +1,58 @@
+package helpers
+
+import (
+	"fmt"
+	"goredis"
+)
+
+/*******************************************************************************
+ * A TestPersistClient that stores objects in Redis, as the SafeHarbor server
+ * does: each object is the value of the key <prefix><id>, in the DBjson format
+ * that ReconstituteObject reads, and IDs are allocated by INCR of the key
+ * <prefix>id. The objects themselves are those of InMemClient.
+ */
+type RedisClient struct {
+	InMemClient  // for its New methods
+	redis *goredis.Redis
+	keyPrefix string
+}
+
+func NewRedisClient(redis *goredis.Redis, keyPrefix string) *RedisClient {
+	return &RedisClient{
+		redis: redis,
+		keyPrefix: keyPrefix,
+	}
+}
+
+func (client *RedisClient) Store(obj Persistable) (string, error) {
+	var n, err = client.redis.Incr(client.keyPrefix + "id")
+	if err != nil { return "", err }
+	var id = fmt.Sprintf("%d", n)
+	err = client.redis.Set(client.keyPrefix + id, obj.toJSON(), 0, 0, false, true)
+	if err != nil { return "", err }
+	return id, nil
+}
+
+func (client *RedisClient) Load(id string) (interface{}, error) {
+	var bytes, err = client.redis.Get(client.keyPrefix + id)
+	if err != nil { return nil, err }
+	if bytes == nil { return nil, ErrObjectNotFound }
+	var obj interface{}
+	_, obj, err = ReconstituteObject(client, string(bytes))
+	if err != nil { return nil, err }
+	return obj, nil
+}
+
+func (client *RedisClient) Update(id string, obj Persistable) error {
+	var reply, err = client.redis.ExecuteCommand("SET", client.keyPrefix + id, obj.toJSON(), "XX")
+	if err != nil { return err }
+	if reply.Type == goredis.BulkReply { return ErrObjectNotFound }  // a nil reply: no such key
+	return reply.OKValue()
+}
+
+func (client *RedisClient) Delete(id string) error {
+	var n, err = client.redis.Del(client.keyPrefix + id)
+	if err != nil { return err }
+	if n == 0 { return ErrObjectNotFound }
+	return nil
+}
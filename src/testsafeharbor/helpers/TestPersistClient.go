@@ -1,14 +1,41 @@
 package helpers
 
 import (
-	"fmt"
-	
+	"time"
+	"errors"
+	"strconv"
+	"sync"
+	"math/big"
 )
 
+/*******************************************************************************
+ * Constructs the persisted types, and stores them. Each stored object has an
+ * ID, which the client allocates.
+ */
 type TestPersistClient interface {
 	NewABC(a int, bs string, car []string, db bool) ABC
+	NewDEF(abc ABC, x int) DEF
+	NewGHI(score float64, size *big.Int, comment *string, abc ABC) GHI
+	NewJKL(abcs []ABC, mixed []interface{}, scores []float64, created time.Time) JKL
+	
+	Store(obj Persistable) (string, error)  // returns the new object's ID
+	Load(id string) (interface{}, error)
+	Update(id string, obj Persistable) error
+	Delete(id string) error
+}
+
+/*******************************************************************************
+ * An object that can be stored: any of the persisted types.
+ */
+type Persistable interface {
+	toJSON() string
 }
 
+/*******************************************************************************
+ * Returned by Load, Update and Delete if there is no object with the ID.
+ */
+var ErrObjectNotFound = errors.New("Object not found")
+
 type ABC interface {
 	getA() int
 	getBs() string
@@ -17,7 +44,83 @@ type ABC interface {
 	toJSON() string
 }
 
+/*******************************************************************************
+ * Register each persisted type, with the New method that constructs it and the
+ * names of its parameters, so that ReconstituteObject binds fields to them by
+ * name. A DEF is also an ABC. The New methods of InMemClient do not use the
+ * client, so one serves for all.
+ */
+var factoryClient = &InMemClient{}
+
+func init() {
+	RegisterType(TypeSpec{
+		Name: "ABC",
+		Factory: factoryClient.NewABC,
+		Interface: (*ABC)(nil),
+		Params: []Param{ RequiredParam("a"), RequiredParam("bs"), RequiredParam("car"),
+			RequiredParam("db") },
+	})
+	RegisterType(TypeSpec{
+		Name: "DEF",
+		Factory: factoryClient.NewDEF,
+		Interface: (*DEF)(nil),
+		Params: []Param{ RequiredParam("ABC"), OptionalParam("xyz", 0) },
+		Parents: []string{ "ABC" },
+	})
+	RegisterType(TypeSpec{
+		Name: "GHI",
+		Factory: factoryClient.NewGHI,
+		Interface: (*GHI)(nil),
+		Params: []Param{ RequiredParam("score"), OptionalParam("size", nil),
+			OptionalParam("comment", nil), OptionalParam("ABC", nil) },
+	})
+	RegisterType(TypeSpec{
+		Name: "JKL",
+		Factory: factoryClient.NewJKL,
+		Interface: (*JKL)(nil),
+		Params: []Param{ RequiredParam("ABC"), OptionalParam("mixed", nil),
+			OptionalParam("scores", nil), OptionalParam("created", nil) },
+	})
+}
+
 type InMemClient struct {
+	lock sync.Mutex
+	objects map[string]Persistable
+	lastId int
+}
+
+func (client *InMemClient) Store(obj Persistable) (string, error) {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	if client.objects == nil { client.objects = make(map[string]Persistable) }
+	client.lastId++
+	var id = strconv.Itoa(client.lastId)
+	client.objects[id] = obj
+	return id, nil
+}
+
+func (client *InMemClient) Load(id string) (interface{}, error) {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	var obj, found = client.objects[id]
+	if ! found { return nil, ErrObjectNotFound }
+	return obj, nil
+}
+
+func (client *InMemClient) Update(id string, obj Persistable) error {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	if _, found := client.objects[id]; ! found { return ErrObjectNotFound }
+	client.objects[id] = obj
+	return nil
+}
+
+func (client *InMemClient) Delete(id string) error {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	if _, found := client.objects[id]; ! found { return ErrObjectNotFound }
+	delete(client.objects, id)
+	return nil
 }
 
 type InMemABC struct {
@@ -48,15 +151,12 @@ func (abc *InMemABC)  getDb() bool {
 	return abc.db
 }
 
+func (abc *InMemABC) JSONFields() []JSONField {
+	return []JSONField{ {"a", abc.a}, {"bs", abc.bs}, {"car", abc.car}, {"db", abc.db} }
+}
+
 func (abc *InMemABC) toJSON() string {
-	var res = fmt.Sprintf("\"ABC\": {\"a\": %d, \"bs\": \"%s\", \"car\": [", abc.a, abc.bs)
-		// Note - need to replace any quotes in abc.bs
-	for i, s := range abc.car {
-		if i > 0 { res = res + ", " }
-		res = res + "\"" + s + "\""  // Note - need to replace any quotes in s
-	}
-	res = res + fmt.Sprintf("], \"db\": %s}", BoolToString(abc.db))
-	return res
+	return mustToJSON("ABC", abc)
 }
 
 type DEF interface {
@@ -69,9 +169,9 @@ type InMemDEF struct {
 	xyz int
 }
 
-func (client *InMemClient) NewDEF(a int, bs string, car []string, db bool, x int) DEF {
+func (client *InMemClient) NewDEF(abc ABC, x int) DEF {
 	var def = &InMemDEF{
-		ABC: client.NewABC(a, bs, car, db),
+		ABC: abc,
 		xyz: x,
 	}
 	return def
@@ -81,15 +181,116 @@ func (def *InMemDEF) getXyz() int {
 	return def.xyz
 }
 
+func (def *InMemDEF) JSONFields() []JSONField {
+	return []JSONField{ {"ABC", def.ABC}, {"xyz", def.xyz} }
+}
+
 func (def *InMemDEF) toJSON() string {
-	var res = fmt.Sprintf("\"DEF\": {\"a\": %d, \"bs\": \"%s\", \"car\": [",
-		def.getA(), def.getBs())
-		// Note - need to replace any quotes in abc.bs
-	for i, s := range def.getCar() {
-		if i > 0 { res = res + ", " }
-		res = res + "\"" + s + "\""  // Note - need to replace any quotes in s
-	}
-	res = res + fmt.Sprintf("], \"db\": %s, \"xyz\": %d}",
-		BoolToString(def.getDb()), def.xyz)
-	return res
+	return mustToJSON("DEF", def)
+}
+
+/*******************************************************************************
+ * A type with optional fields (comment and abc may be nil), a floating point
+ * field, and an integer field that may be too large for an int.
+ */
+type GHI interface {
+	getScore() float64
+	getSize() *big.Int
+	getComment() *string
+	getABC() ABC
+	toJSON() string
+}
+
+type InMemGHI struct {
+	score float64
+	size *big.Int
+	comment *string
+	abc ABC
+}
+
+func (client *InMemClient) NewGHI(score float64, size *big.Int, comment *string, abc ABC) GHI {
+	return &InMemGHI{score, size, comment, abc}
+}
+
+func (ghi *InMemGHI) getScore() float64 {
+	return ghi.score
+}
+
+func (ghi *InMemGHI) getSize() *big.Int {
+	return ghi.size
+}
+
+func (ghi *InMemGHI) getComment() *string {
+	return ghi.comment
+}
+
+func (ghi *InMemGHI) getABC() ABC {
+	return ghi.abc
+}
+
+func (ghi *InMemGHI) JSONFields() []JSONField {
+	return []JSONField{ {"score", ghi.score}, {"size", ghi.size}, {"comment", ghi.comment},
+		{"ABC", ghi.abc} }
+}
+
+func (ghi *InMemGHI) toJSON() string {
+	return mustToJSON("GHI", ghi)
+}
+
+/*******************************************************************************
+ * A type with array fields - an array of objects, an array of mixed values, and
+ * an array of floats - and a time field.
+ */
+type JKL interface {
+	getABCs() []ABC
+	getMixed() []interface{}
+	getScores() []float64
+	getCreated() time.Time
+	toJSON() string
+}
+
+type InMemJKL struct {
+	abcs []ABC
+	mixed []interface{}
+	scores []float64
+	created time.Time
+}
+
+func (client *InMemClient) NewJKL(abcs []ABC, mixed []interface{}, scores []float64,
+	created time.Time) JKL {
+	return &InMemJKL{abcs, mixed, scores, created}
+}
+
+func (jkl *InMemJKL) getABCs() []ABC {
+	return jkl.abcs
+}
+
+func (jkl *InMemJKL) getMixed() []interface{} {
+	return jkl.mixed
+}
+
+func (jkl *InMemJKL) getScores() []float64 {
+	return jkl.scores
+}
+
+func (jkl *InMemJKL) getCreated() time.Time {
+	return jkl.created
+}
+
+func (jkl *InMemJKL) JSONFields() []JSONField {
+	return []JSONField{ {"ABC", jkl.abcs}, {"mixed", jkl.mixed}, {"scores", jkl.scores},
+		{"created", jkl.created} }
+}
+
+func (jkl *InMemJKL) toJSON() string {
+	return mustToJSON("JKL", jkl)
+}
+
+/*******************************************************************************
+ * The InMem types hold only values that ToJSON can serialize.
+ */
+func mustToJSON(typeName string, object interface{}) string {
+	var json, err = ToJSON(typeName, object)
+	if err != nil { panic(err) }
+	return json
 }
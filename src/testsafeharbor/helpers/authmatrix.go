@@ -0,0 +1,465 @@
+/*******************************************************************************
+ * A generator of authorization tests, over a matrix of parties (the realm's
+ * admin, an ordinary user of the realm, a user of another realm, etc.) and
+ * resources (the realm, a repo, a dockerfile, etc.). One test is performed per
+ * cell of the matrix: a fresh realm, party and resource are created, and then,
+ * for each permission bit, the request that requires that permission on the
+ * resource is sent as the party, and is asserted to be allowed or denied.
+ *
+ * Each bit is exercised twice: before the party has any permission on the
+ * resource, and then after it (or, for a group member, its group) has been
+ * granted only that bit. The permission granted for one bit is removed before
+ * the next bit is exercised. A party that cannot be granted permissions (the
+ * realm admin, a user of another realm, or an anonymous party) is exercised
+ * once. Bits that no request requires of the resource cannot be exercised, and
+ * are reported with a warning. Requests that delete the resource are sent last.
+ */
+
+package helpers
+
+import (
+	"fmt"
+	"os"
+
+	"testsafeharbor/client"
+)
+
+const (
+	PartyRealmAdmin = "RealmAdmin"
+	PartyRealmUser = "RealmUser"
+	PartyOtherRealmUser = "OtherRealmUser"
+	PartyGroupMember = "GroupMember"
+	PartyAnonymous = "Anonymous"
+	PartyDisabledUser = "DisabledUser"
+)
+
+const (
+	ResourceRealm = "Realm"
+	ResourceRepo = "Repo"
+	ResourceDockerfile = "Dockerfile"
+	ResourceImage = "Image"
+	ResourceScanConfig = "ScanConfig"
+	ResourceFlag = "Flag"
+)
+
+var AllParties = []string{ PartyRealmAdmin, PartyRealmUser, PartyOtherRealmUser,
+	PartyGroupMember, PartyAnonymous, PartyDisabledUser }
+
+var AllResources = []string{ ResourceRealm, ResourceRepo, ResourceDockerfile,
+	ResourceImage, ResourceScanConfig, ResourceFlag }
+
+// The bits of a permission mask, in the order of client.PermissionMask.ToSlice.
+var permissionBitNames = []string{ "CanCreateIn", "CanRead", "CanWrite", "CanExecute", "CanDelete" }
+
+type AuthMatrix struct {
+	Parties []string
+	Resources []string
+	FlagImagePath string  // the image for the flags that are created
+}
+
+/*******************************************************************************
+ * Return a matrix of all parties and resources. The cells that are tested can
+ * be reduced by removing entries from Parties and Resources.
+ */
+func NewAuthMatrix(flagImagePath string) *AuthMatrix {
+	return &AuthMatrix{
+		Parties: AllParties,
+		Resources: AllResources,
+		FlagImagePath: flagImagePath,
+	}
+}
+
+/*******************************************************************************
+ * The realm and objects created for one cell of the matrix. Objects that the
+ * cell's resource does not need are not created.
+ */
+type authFixture struct {
+	testContext *TestContext
+	cellName string
+	nameCount int
+	dockerfilePath string
+	flagImagePath string
+	admin *client.Client
+	realmId string
+	repoId string
+	dockerfileId string
+	imageId string
+	scanConfigId string
+	flagId string
+}
+
+/*******************************************************************************
+ * Sends the request that requires one permission bit on the cell's resource.
+ */
+type authProbe struct {
+	reqName string
+	send func(c *client.Client, fixture *authFixture) error
+}
+
+/*******************************************************************************
+ * For each kind of resource, the probe for each permission bit, or nil if no
+ * request requires that bit of the resource.
+ */
+var authProbes = map[string][5]*authProbe{
+	ResourceRealm: {
+		&authProbe{ "createRepo", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.CreateRepo(fixture.realmId, fixture.newName("repo"), "A repo", "")
+			return err
+		}},
+		&authProbe{ "getRealmRepos", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.GetRealmRepos(fixture.realmId)
+			return err
+		}},
+		&authProbe{ "createGroup", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.CreateGroup(fixture.realmId, fixture.newName("group"), "A group", false)
+			return err
+		}},
+		nil,
+		&authProbe{ "deactivateRealm", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.DeactivateRealm(fixture.realmId)
+			return err
+		}},
+	},
+	ResourceRepo: {
+		&authProbe{ "addDockerfile", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.AddDockerfile(fixture.repoId, fixture.dockerfilePath, "A dockerfile")
+			return err
+		}},
+		&authProbe{ "getRepoDesc", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.GetRepoDesc(fixture.repoId)
+			return err
+		}},
+		nil,
+		nil,
+		&authProbe{ "deleteRepo", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.DeleteRepo(fixture.repoId)
+			return err
+		}},
+	},
+	ResourceDockerfile: {
+		nil,
+		&authProbe{ "getDockerfileDesc", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.GetDockerfileDesc(fixture.dockerfileId)
+			return err
+		}},
+		&authProbe{ "replaceDockerfile", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.ReplaceDockerfile(fixture.dockerfileId, fixture.dockerfilePath,
+				"A replaced dockerfile")
+			return err
+		}},
+		&authProbe{ "execDockerfile", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.ExecDockerfile(fixture.repoId, fixture.dockerfileId,
+				fixture.newName("image"), []string{}, []string{})
+			return err
+		}},
+		&authProbe{ "remDockerfile", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.RemDockerfile(fixture.dockerfileId)
+			return err
+		}},
+	},
+	ResourceImage: {
+		nil,
+		&authProbe{ "getDockerImageDesc", func(c *client.Client, fixture *authFixture) error {
+			var _, _, err = c.GetDockerImageDesc(fixture.imageId)
+			return err
+		}},
+		&authProbe{ "stopUsingScanConfigForImage", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.StopUsingScanConfigForImage(fixture.imageId, fixture.scanConfigId)
+			return err
+		}},
+		nil,
+		&authProbe{ "remDockerImage", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.RemDockerImage(fixture.imageId)
+			return err
+		}},
+	},
+	ResourceScanConfig: {
+		nil,
+		&authProbe{ "getScanConfigDesc", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.GetScanConfigDesc(fixture.scanConfigId)
+			return err
+		}},
+		&authProbe{ "updateScanConfig", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.UpdateScanConfig(fixture.scanConfigId, "", "An updated scan config",
+				"", "", "", []string{}, []string{})
+			return err
+		}},
+		nil,
+		&authProbe{ "remScanConfig", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.RemScanConfig(fixture.scanConfigId)
+			return err
+		}},
+	},
+	ResourceFlag: {
+		nil,
+		&authProbe{ "getFlagDesc", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.GetFlagDesc(fixture.flagId)
+			return err
+		}},
+		nil,
+		nil,
+		&authProbe{ "remFlag", func(c *client.Client, fixture *authFixture) error {
+			var _, err = c.RemFlag(fixture.flagId)
+			return err
+		}},
+	},
+}
+
+/*******************************************************************************
+ * Return true if the party should be allowed a request that requires a
+ * permission on a resource in the realm, before or after it has been granted
+ * that permission.
+ */
+func isAuthExpected(party string, granted bool) bool {
+	switch party {
+	case PartyRealmAdmin: return true
+	case PartyRealmUser, PartyGroupMember: return granted
+	default: return false  // a user of another realm, anonymous, or disabled
+	}
+}
+
+/*******************************************************************************
+ * Perform one test for each cell of the matrix.
+ */
+func (testContext *TestContext) TryAuthMatrix(matrix *AuthMatrix) {
+
+	var tempdir, err = CreateTempDir()
+	if err != nil { testContext.AbortAllTests(err.Error()) }
+	defer os.RemoveAll(tempdir)
+	var dockerfilePath string
+	dockerfilePath, err = CreateTempFile(tempdir, "Dockerfile", "FROM centos\nRUN echo moo > oink")
+	if err != nil { testContext.AbortAllTests(err.Error()) }
+
+	for _, resource := range matrix.Resources {
+		for bit, probe := range authProbes[resource] {
+			if probe != nil { continue }
+			fmt.Println(fmt.Sprintf("Warning: %s on %s is not tested: no request requires it",
+				permissionBitNames[bit], resource))
+		}
+	}
+
+	var cellNo = 0
+	for _, resource := range matrix.Resources {
+		for _, party := range matrix.Parties {
+			cellNo++
+			var fixture = &authFixture{
+				testContext: testContext,
+				cellName: fmt.Sprintf("authcell%d", cellNo),
+				dockerfilePath: dockerfilePath,
+				flagImagePath: matrix.FlagImagePath,
+			}
+			testContext.tryAuthMatrixCell(fixture, party, resource)
+		}
+	}
+}
+
+/*******************************************************************************
+ * Perform the test for one cell of the matrix.
+ */
+func (testContext *TestContext) tryAuthMatrixCell(fixture *authFixture, party, resource string) {
+
+	testContext.StartTest("TryAuthMatrix " + party + " " + resource)
+
+	var err = fixture.createRealm()
+	if ! testContext.AssertErrIsNil(err, "When creating the realm for the test") { return }
+	var resourceId string
+	resourceId, err = fixture.createResource(resource)
+	if ! testContext.AssertErrIsNil(err, "When creating the " + resource) { return }
+	var partyClient *client.Client
+	var granteeId string
+	partyClient, granteeId, err = fixture.createParty(party)
+	if ! testContext.AssertErrIsNil(err, "When creating the " + party) { return }
+
+	var grantedBit = -1  // none
+	for bit, probe := range authProbes[resource] {
+		if probe == nil { continue }
+		if grantedBit != -1 {  // so that the party again has no permission on the resource
+			_, err = fixture.admin.RemPermission(granteeId, resourceId)
+			var revoked = permissionBitNames[grantedBit]
+			if ! testContext.AssertErrIsNil(err, "When revoking " + revoked) { return }
+			grantedBit = -1
+		}
+		var what = fmt.Sprintf("%s by %s (requires %s on %s)", probe.reqName, party,
+			permissionBitNames[bit], resource)
+		testContext.assertAuthorized(isAuthExpected(party, false), probe.send(partyClient, fixture), what)
+
+		if granteeId == "" { continue }
+		var permissions = make([]bool, 5)
+		permissions[bit] = true
+		_, err = fixture.admin.SetPermission(granteeId, resourceId, client.NewPermissionMask(permissions))
+		if ! testContext.AssertErrIsNil(err, "When granting " + permissionBitNames[bit]) { return }
+		grantedBit = bit
+		testContext.assertAuthorized(isAuthExpected(party, true), probe.send(partyClient, fixture),
+			what + ", after it was granted")
+	}
+
+	testContext.PassTestIfNoFailures()
+}
+
+/*******************************************************************************
+ * Assert that a request was allowed, or that it was denied - i.e., that the
+ * server refused it with status 401 or 403, rather than for some other reason.
+ */
+func (testContext *TestContext) assertAuthorized(expectAllowed bool, err error, what string) {
+	if expectAllowed {
+		testContext.AssertErrIsNil(err, what + " should be allowed")
+		return
+	}
+	if err == nil {
+		testContext.AssertThat(false, what + " should be denied, but was allowed")
+		return
+	}
+	var status = client.GetStatusCode(err)
+	if testContext.AssertThat((status == 401) || (status == 403),
+		what + " should be denied, but failed otherwise: " + err.Error()) {
+		fmt.Println("Denied as expected: " + what)
+	}
+}
+
+/*******************************************************************************
+ * Return a name, unique to the cell, with the specified prefix.
+ */
+func (fixture *authFixture) newName(prefix string) string {
+	fixture.nameCount++
+	return fixture.testContext.Unique(fmt.Sprintf("%s%s%d", fixture.cellName, prefix,
+		fixture.nameCount))
+}
+
+func (fixture *authFixture) newClient() *client.Client {
+	return client.NewClient(fixture.testContext, "", fixture.testContext.TestDemarcation())
+}
+
+/*******************************************************************************
+ * Create the cell's realm, and log in as its admin.
+ */
+func (fixture *authFixture) createRealm() error {
+	var adminUserId = fixture.newName("admin")
+	fixture.admin = fixture.newClient()
+	var adminDesc, err = fixture.admin.CreateRealmAnon(fixture.newName("realm"),
+		"Authorization Matrix Org", adminUserId, "Realm Admin", adminUserId + "@gmail.com", "fluffy")
+	if err != nil { return err }
+	fixture.realmId = adminDesc.RealmId
+	_, err = fixture.admin.Authenticate(adminUserId, "fluffy")
+	return err
+}
+
+/*******************************************************************************
+ * Create, as the realm admin, the resource and the objects that its probes
+ * need. Return the Id of the resource.
+ */
+func (fixture *authFixture) createResource(resource string) (string, error) {
+
+	var admin = fixture.admin
+	if resource == ResourceRealm { return fixture.realmId, nil }
+
+	var repoDesc, err = admin.CreateRepo(fixture.realmId, fixture.newName("repo"), "A repo", "")
+	if err != nil { return "", err }
+	fixture.repoId = repoDesc.Id
+
+	switch resource {
+	case ResourceRepo:
+		return fixture.repoId, nil
+	case ResourceDockerfile, ResourceImage:
+		var dockerfileDesc *client.DockerfileDesc
+		dockerfileDesc, err = admin.AddDockerfile(fixture.repoId, fixture.dockerfilePath, "A dockerfile")
+		if err != nil { return "", err }
+		fixture.dockerfileId = dockerfileDesc.Id
+		if resource == ResourceDockerfile { return fixture.dockerfileId, nil }
+
+		// The image's write probe stops using a scan config.
+		var versionDesc *client.DockerImageVersionDesc
+		versionDesc, err = admin.ExecDockerfile(fixture.repoId, fixture.dockerfileId,
+			fixture.newName("image"), []string{}, []string{})
+		if err != nil { return "", err }
+		fixture.imageId = versionDesc.ImageObjId
+		err = fixture.createScanConfig()
+		if err != nil { return "", err }
+		_, err = admin.UseScanConfigForImage(fixture.imageId, fixture.scanConfigId)
+		if err != nil { return "", err }
+		return fixture.imageId, nil
+	case ResourceScanConfig:
+		err = fixture.createScanConfig()
+		if err != nil { return "", err }
+		return fixture.scanConfigId, nil
+	case ResourceFlag:
+		var flagDesc *client.FlagDesc
+		flagDesc, err = admin.DefineFlag(fixture.repoId, fixture.newName("flag"), "A flag",
+			fixture.flagImagePath)
+		if err != nil { return "", err }
+		fixture.flagId = flagDesc.FlagId
+		return fixture.flagId, nil
+	default:
+		panic("Unrecognized resource: " + resource)
+	}
+}
+
+func (fixture *authFixture) createScanConfig() error {
+	var scanConfigDesc, err = fixture.admin.DefineScanConfig(fixture.newName("config"),
+		"A scan config", fixture.repoId, "clair", "", "", []string{}, []string{})
+	if err != nil { return err }
+	fixture.scanConfigId = scanConfigDesc.Id
+	return nil
+}
+
+/*******************************************************************************
+ * Create the party, and return a client that is logged in as the party, and
+ * the Id to which permissions for the party are granted - or "" if the party
+ * is not granted permissions.
+ */
+func (fixture *authFixture) createParty(party string) (*client.Client, string, error) {
+
+	var admin = fixture.admin
+	switch party {
+	case PartyRealmAdmin:
+		return admin, "", nil
+	case PartyAnonymous:
+		return fixture.newClient(), "", nil
+	case PartyOtherRealmUser:
+		// Another realm, with an ordinary user.
+		var otherFixture = &authFixture{
+			testContext: fixture.testContext,
+			cellName: fixture.cellName + "other",
+		}
+		var err = otherFixture.createRealm()
+		if err != nil { return nil, "", err }
+		var userClient *client.Client
+		userClient, _, err = otherFixture.createUser()
+		return userClient, "", err
+	}
+
+	var userClient, userObjId, err = fixture.createUser()
+	if err != nil { return nil, "", err }
+	switch party {
+	case PartyRealmUser:
+		return userClient, userObjId, nil
+	case PartyGroupMember:
+		var groupDesc *client.GroupDesc
+		groupDesc, err = admin.CreateGroup(fixture.realmId, fixture.newName("group"), "A group", false)
+		if err != nil { return nil, "", err }
+		_, err = admin.AddGroupUser(groupDesc.Id, userObjId)
+		if err != nil { return nil, "", err }
+		return userClient, groupDesc.Id, nil
+	case PartyDisabledUser:
+		// Disabled after logging in, so that the user's session is exercised.
+		_, err = admin.DisableUser(userObjId)
+		if err != nil { return nil, "", err }
+		return userClient, userObjId, nil
+	default:
+		panic("Unrecognized party: " + party)
+	}
+}
+
+/*******************************************************************************
+ * Create an ordinary user in the realm, and return a client that is logged in
+ * as the user, and the user's object Id.
+ */
+func (fixture *authFixture) createUser() (*client.Client, string, error) {
+	var userId = fixture.newName("user")
+	var userDesc, err = fixture.admin.CreateUser(userId, "Ordinary User", userId + "@gmail.com",
+		"I am never safe", fixture.realmId)
+	if err != nil { return nil, "", err }
+	var userClient = fixture.newClient()
+	_, err = userClient.Authenticate(userId, "I am never safe")
+	if err != nil { return nil, "", err }
+	return userClient, userDesc.Id, nil
+}
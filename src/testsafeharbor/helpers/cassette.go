@@ -0,0 +1,315 @@
+/*******************************************************************************
+ * Record and replay of the REST conversation with the SafeHarbor server.
+ *
+ * In record mode, each request that is sent through TestContext.SendSessionPost,
+ * SendSessionGet or SendSessionFilePost - and the response to it - is written
+ * to a "cassette" file, <dir>/<suite>/<sequence no>-<request name>.json.
+ *
+ * In replay mode, requests are sent to a local stand-in server, which returns
+ * the recorded responses, in sequence, for the current suite. A request that
+ * does not match the next recorded request - in its method, its name (the path
+ * of its URL), its form values, or the name of the file that it posts - yields
+ * a 500 response, so that the test that sent it fails.
+ */
+
+package helpers
+
+import (
+	"fmt"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"bytes"
+	"strings"
+	"sort"
+	"sync"
+	"unicode/utf8"
+	"encoding/json"
+	"encoding/base64"
+)
+
+/*******************************************************************************
+ * The content of a cassette file: one request and the response to it.
+ */
+type Cassette struct {
+	Method string  // "GET", "POST", or "FILEPOST"
+	ReqName string
+	WithSession bool  // true if a session Id was sent
+	Names []string
+	Values []string
+	FileName string `json:",omitempty"`  // base name of the file sent, for FILEPOST
+	Error string `json:",omitempty"`  // set if no response was received
+	Response *CassetteResponse `json:",omitempty"`
+}
+
+type CassetteResponse struct {
+	StatusCode int
+	Status string
+	ContentType string `json:",omitempty"`
+	// Exactly one of these is set, depending on the content of the body:
+	// JSON is used if the body is valid JSON, so that cassettes diff well.
+	JSON json.RawMessage `json:",omitempty"`
+	Text string `json:",omitempty"`
+	Base64 string `json:",omitempty"`
+}
+
+/*******************************************************************************
+ * Build a CassetteResponse from an http response. The response's body is
+ * consumed, and replaced with an equivalent body so that the caller can still
+ * read it.
+ */
+func newCassetteResponse(resp *http.Response) (*CassetteResponse, error) {
+	var body []byte
+	var err error
+	body, err = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil { return nil, err }
+
+	var cassetteResponse = &CassetteResponse{
+		StatusCode: resp.StatusCode,
+		Status: resp.Status,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if (len(body) > 0) && json.Valid(body) {
+		cassetteResponse.JSON = json.RawMessage(body)
+	} else if utf8.Valid(body) {
+		cassetteResponse.Text = string(body)
+	} else {
+		cassetteResponse.Base64 = base64.StdEncoding.EncodeToString(body)
+	}
+	return cassetteResponse, nil
+}
+
+/*******************************************************************************
+ * Return the body of the recorded response.
+ */
+func (cassetteResponse *CassetteResponse) GetBody() ([]byte, error) {
+	if len(cassetteResponse.JSON) > 0 { return []byte(cassetteResponse.JSON), nil }
+	if cassetteResponse.Base64 != "" {
+		return base64.StdEncoding.DecodeString(cassetteResponse.Base64)
+	}
+	return []byte(cassetteResponse.Text), nil
+}
+
+/*******************************************************************************
+ * Replace characters that are not suitable for a file name.
+ */
+func cassetteFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+			r == '-' || r == '_' || r == '.' { return r }
+		return '_'
+	}, name)
+}
+
+/*******************************************************************************
+ * Writes cassettes for the requests sent by one TestContext.
+ */
+type CassetteRecorder struct {
+	Dir string
+	suiteName string
+	sequenceNo int
+}
+
+func NewCassetteRecorder(dir string) *CassetteRecorder {
+	return &CassetteRecorder{ Dir: dir }
+}
+
+/*******************************************************************************
+ * Begin recording the requests of the specified suite. Cassettes that were
+ * previously recorded for the suite are removed, so that a stale cassette does
+ * not get replayed.
+ */
+func (recorder *CassetteRecorder) StartSuite(suiteName string) error {
+	recorder.suiteName = suiteName
+	recorder.sequenceNo = 0
+	var suiteDir = filepath.Join(recorder.Dir, cassetteFileName(suiteName))
+	var err = os.RemoveAll(suiteDir)
+	if err != nil { return err }
+	return os.MkdirAll(suiteDir, 0755)
+}
+
+/*******************************************************************************
+ * Write a cassette for the specified request and response.
+ */
+func (recorder *CassetteRecorder) Record(cassette *Cassette) error {
+	recorder.sequenceNo++
+	var path = filepath.Join(recorder.Dir, cassetteFileName(recorder.suiteName),
+		fmt.Sprintf("%04d-%s.json", recorder.sequenceNo, cassetteFileName(cassette.ReqName)))
+	var buffer bytes.Buffer
+	var encoder = json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)  // the Log value contains '<' characters
+	encoder.SetIndent("", "\t")
+	var err = encoder.Encode(cassette)
+	if err != nil { return err }
+	return ioutil.WriteFile(path, buffer.Bytes(), 0644)
+}
+
+/*******************************************************************************
+ * A local stand-in server that replays the cassettes in a directory. Each
+ * TestContext has its own player, so that suites that run in parallel do not
+ * interfere with each other.
+ */
+type CassettePlayer struct {
+	Dir string
+	listener net.Listener
+	lock sync.Mutex
+	suiteName string
+	cassettes []*Cassette
+	cassetteFiles []string
+	next int
+}
+
+/*******************************************************************************
+ * Start a player on a free local port.
+ */
+func NewCassettePlayer(dir string) (*CassettePlayer, error) {
+	var info os.FileInfo
+	var err error
+	info, err = os.Stat(dir)
+	if err != nil { return nil, err }
+	if ! info.IsDir() { return nil, errors.New(dir + " is not a directory") }
+
+	var player = &CassettePlayer{ Dir: dir }
+	player.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil { return nil, err }
+	go http.Serve(player.listener, player)
+	return player, nil
+}
+
+/*******************************************************************************
+ * Return the port that the player is listening on.
+ */
+func (player *CassettePlayer) GetPort() int {
+	return player.listener.Addr().(*net.TCPAddr).Port
+}
+
+/*******************************************************************************
+ * Stop listening. TestContext.FinishTests closes the player of its context.
+ */
+func (player *CassettePlayer) Close() error {
+	return player.listener.Close()
+}
+
+/*******************************************************************************
+ * Load the cassettes of the specified suite, and begin replaying them from the
+ * first one. A suite for which there are no cassettes is not an error: the
+ * suite might not use the SafeHarbor server.
+ */
+func (player *CassettePlayer) StartSuite(suiteName string) error {
+	player.lock.Lock()
+	defer player.lock.Unlock()
+
+	player.suiteName = suiteName
+	player.cassettes = []*Cassette{}
+	player.cassetteFiles = []string{}
+	player.next = 0
+
+	var paths []string
+	var err error
+	paths, err = filepath.Glob(filepath.Join(player.Dir, cassetteFileName(suiteName), "*.json"))
+	if err != nil { return err }
+	sort.Strings(paths)  // file names begin with a zero-padded sequence no.
+	for _, path := range paths {
+		var bytes []byte
+		bytes, err = ioutil.ReadFile(path)
+		if err != nil { return err }
+		var cassette = &Cassette{}
+		err = json.Unmarshal(bytes, cassette)
+		if err != nil { return errors.New(path + ": " + err.Error()) }
+		player.cassettes = append(player.cassettes, cassette)
+		player.cassetteFiles = append(player.cassetteFiles, path)
+	}
+	return nil
+}
+
+/*******************************************************************************
+ * Serve the next recorded response, if the request matches the next recorded
+ * request.
+ */
+func (player *CassettePlayer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	player.lock.Lock()
+	defer player.lock.Unlock()
+
+	var reqName = strings.TrimPrefix(request.URL.Path, "/")
+	if player.next >= len(player.cassettes) {
+		http.Error(writer, fmt.Sprintf("No more cassettes for suite %s; request was %s",
+			player.suiteName, reqName), http.StatusInternalServerError)
+		return
+	}
+	var cassette = player.cassettes[player.next]
+	var path = player.cassetteFiles[player.next]
+	player.next++
+
+	if cassette.ReqName != reqName {
+		http.Error(writer, fmt.Sprintf("Expected request %s (%s), but received %s",
+			cassette.ReqName, path, reqName), http.StatusInternalServerError)
+		return
+	}
+	var mismatch = cassette.mismatch(request)
+	if mismatch != "" {
+		http.Error(writer, fmt.Sprintf("Request %s does not match %s: %s",
+			reqName, path, mismatch), http.StatusInternalServerError)
+		return
+	}
+	if cassette.Response == nil {
+		http.Error(writer, fmt.Sprintf("No response was recorded for %s: %s",
+			path, cassette.Error), http.StatusBadGateway)
+		return
+	}
+
+	var body []byte
+	var err error
+	body, err = cassette.Response.GetBody()
+	if err != nil {
+		http.Error(writer, path + ": " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if cassette.Response.ContentType != "" {
+		writer.Header().Set("Content-Type", cassette.Response.ContentType)
+	}
+	writer.WriteHeader(cassette.Response.StatusCode)
+	writer.Write(body)
+}
+
+/*******************************************************************************
+ * Return how the request differs from the recorded one, in its method, its form
+ * values, or the name of the file that it posts - or "" if it does not.
+ */
+func (cassette *Cassette) mismatch(request *http.Request) string {
+
+	var method = request.Method
+	var err error
+	if strings.HasPrefix(request.Header.Get("Content-Type"), "multipart/form-data") {
+		if method == "POST" { method = "FILEPOST" }
+		err = request.ParseMultipartForm(32 << 20)
+	} else {
+		err = request.ParseForm()
+	}
+	if method != cassette.Method { return fmt.Sprintf("method is %s, expected %s",
+		method, cassette.Method) }
+	if err != nil { return "Unable to parse the form: " + err.Error() }
+
+	var recorded = url.Values{}
+	for i, name := range cassette.Names {
+		if i < len(cassette.Values) { recorded.Add(name, cassette.Values[i]) }
+	}
+	if request.Form.Encode() != recorded.Encode() {
+		return fmt.Sprintf("form values are %s, expected %s",
+			request.Form.Encode(), recorded.Encode())
+	}
+
+	if cassette.Method != "FILEPOST" { return "" }
+	var fileName = ""
+	for _, fileHeaders := range request.MultipartForm.File {
+		if len(fileHeaders) > 0 { fileName = fileHeaders[0].Filename }
+	}
+	if fileName != cassette.FileName { return fmt.Sprintf("file is %q, expected %q",
+		fileName, cassette.FileName) }
+	return ""
+}
@@ -0,0 +1,91 @@
+package helpers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+/*******************************************************************************
+ * Write one cassette, for a getRealmDesc request, to a suite in a temp dir, and
+ * return the dir.
+ */
+func writeTestCassette(t *testing.T) string {
+	var dir, err = ioutil.TempDir("", "cassettes")
+	if err != nil { t.Fatal(err) }
+	var recorder = NewCassetteRecorder(dir)
+	err = recorder.StartSuite("Suite")
+	if err != nil { t.Fatal(err) }
+	err = recorder.Record(&Cassette{
+		Method: "POST",
+		ReqName: "getRealmDesc",
+		Names: []string{ "RealmId" },
+		Values: []string{ "123" },
+		Response: &CassetteResponse{ StatusCode: 200, Status: "200 OK", Text: "recorded" },
+	})
+	if err != nil { t.Fatal(err) }
+	return dir
+}
+
+/*******************************************************************************
+ * A request is replayed only if its method, name and form values are those
+ * that were recorded.
+ */
+func TestCassettePlayerMatchesRequest(t *testing.T) {
+	var dir = writeTestCassette(t)
+	defer os.RemoveAll(dir)
+	var player, err = NewCassettePlayer(dir)
+	if err != nil { t.Fatal(err) }
+	defer player.Close()
+	var reqURL = fmt.Sprintf("http://127.0.0.1:%d/getRealmDesc", player.GetPort())
+
+	var send = func(method string, values url.Values) (int, string) {
+		err = player.StartSuite("Suite")
+		if err != nil { t.Fatal(err) }
+		var resp *http.Response
+		if method == "GET" {
+			resp, err = http.Get(reqURL + "?" + values.Encode())
+		} else {
+			resp, err = http.PostForm(reqURL, values)
+		}
+		if err != nil { t.Fatal(err) }
+		defer resp.Body.Close()
+		var body, _ = ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	var status, body = send("POST", url.Values{ "RealmId": {"123"} })
+	if (status != 200) || (body != "recorded") { t.Errorf("Matching request: %d %s", status, body) }
+	status, body = send("POST", url.Values{ "RealmId": {"456"} })
+	if (status != 500) || ! strings.Contains(body, "form values are RealmId=456, expected RealmId=123") {
+		t.Errorf("Request with other values: %d %s", status, body)
+	}
+	status, body = send("GET", url.Values{ "RealmId": {"123"} })
+	if (status != 500) || ! strings.Contains(body, "method is GET, expected POST") {
+		t.Errorf("Request with another method: %d %s", status, body)
+	}
+}
+
+/*******************************************************************************
+ * The player of a TestContext stops listening when the tests finish.
+ */
+func TestFinishTestsClosesCassettePlayer(t *testing.T) {
+	var dir = writeTestCassette(t)
+	defer os.RemoveAll(dir)
+	var testContext = NewTestContext("http", "127.0.0.1", 0, SetSessionId, false, "", true)
+	var err = testContext.ReplayCassettesFrom(dir)
+	if err != nil { t.Fatal(err) }
+	var address = fmt.Sprintf("127.0.0.1:%d", testContext.cassettePlayer.GetPort())
+	testContext.FinishTests()
+	var conn net.Conn
+	conn, err = net.Dial("tcp", address)
+	if err == nil {
+		conn.Close()
+		t.Errorf("The cassette player is still listening on %s", address)
+	}
+}
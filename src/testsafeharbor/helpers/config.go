@@ -0,0 +1,237 @@
+/*******************************************************************************
+ * The configuration of the test runner: how to reach the SafeHarbor server,
+ * and the services that some suites need (a docker registry, an email service,
+ * Twistlock). A configuration file is JSON; its top-level settings apply to all
+ * profiles, and each named profile (e.g., "local", "staging", "ci") overrides
+ * some of them. See testsafeharbor.example.json.
+ *
+ * Settings are taken, in increasing order of precedence, from DefaultConfig,
+ * the file, the selected profile, the environment variables named by the "env"
+ * tags below, and the command line. A secret may be given as "env:<name>", to
+ * read it from an environment variable, or as "file:<path>", to read it from a
+ * file, so that secrets need not appear in the configuration file - and none
+ * are compiled into the runner. A secret that cannot be resolved is left empty,
+ * with a warning: only the suites that need it fail.
+ */
+
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"io/ioutil"
+	"encoding/json"
+)
+
+type Config struct {
+	Scheme string `env:"SAFEHARBOR_SCHEME"`
+	Host string `env:"SAFEHARBOR_HOST"`
+	Port int `env:"SAFEHARBOR_PORT"`
+	StopOnFirstError bool
+	NoLargeFileTransfers bool
+	TestTimeout string `env:"SAFEHARBOR_TEST_TIMEOUT"`  // e.g., "5m"; "0" for no limit
+	RedisPswd string `env:"SAFEHARBOR_REDIS_PASSWORD" secret:"true"`
+	RedisSentinels string `env:"SAFEHARBOR_REDIS_SENTINELS"`  // e.g., "host1:26379,host2:26379"; "" to connect directly
+	RedisMaster string `env:"SAFEHARBOR_REDIS_MASTER"`  // the name under which the sentinels monitor Redis
+	Registry RegistryConfig
+	Email EmailConfig
+	Twistlock TwistlockConfig
+}
+
+/*******************************************************************************
+ * The docker registry, for the Registry and Engine suites. The environment
+ * variables are those that the makefile sets.
+ */
+type RegistryConfig struct {
+	Host string `env:"RegistryHost"`
+	Port int `env:"RegistryPort"`
+	User string `env:"registryUser"`
+	Password string `env:"registryPassword" secret:"true"`
+	TestImageRepoName string `env:"TestImageRepoName"`
+	TestImageTag string `env:"TestImageTag"`
+	ImageToUploadPath string `env:"ImageToUploadPath"`
+	ImageToUploadDigest string `env:"ImageToUploadDigest"`
+}
+
+/*******************************************************************************
+ * The SES SMTP service, for the Email suite.
+ */
+type EmailConfig struct {
+	SMTPHostname string `env:"SES_SMTP_HOSTNAME"`
+	SMTPPort int `env:"SES_SMTP_PORT"`
+	SenderAddress string `env:"EMAIL_SENDER_ADDRESS"`
+	SenderUserId string `env:"EMAIL_SENDER_USER_ID" secret:"true"`
+	SenderPassword string `env:"EMAIL_SENDER_PASSWORD" secret:"true"`
+	RecipientAddress string `env:"EMAIL_RECIPIENT_ADDRESS"`
+}
+
+/*******************************************************************************
+ * The Twistlock service, for the TwistlockStandalone suite.
+ */
+type TwistlockConfig struct {
+	Host string `env:"TWISTLOCK_HOST"`
+	Port int `env:"TWISTLOCK_PORT"`
+	UserId string `env:"TWISTLOCK_USER_ID"`
+	Password string `env:"TWISTLOCK_PASSWORD" secret:"true"`
+}
+
+/*******************************************************************************
+ * The layout of a configuration file: the settings of Config, plus profiles.
+ */
+type configFile struct {
+	DefaultProfile string
+	Profiles map[string]json.RawMessage
+}
+
+/*******************************************************************************
+ * Return the settings that apply when none are configured.
+ */
+func DefaultConfig() *Config {
+	return &Config{
+		Scheme: "http",
+		Host: "localhost",
+		Port: 80,
+		TestTimeout: "10m",
+		RedisMaster: "mymaster",
+		Email: EmailConfig{
+			SMTPHostname: "email-smtp.us-west-2.amazonaws.com",
+			SMTPPort: 25,
+			SenderAddress: "cliff_test@cliffberg.com",
+			RecipientAddress: "cliff_cromarti@cliffberg.com",
+		},
+		Twistlock: TwistlockConfig{
+			Host: "127.0.0.1",
+			Port: 8083,
+			UserId: "admin",
+		},
+	}
+}
+
+/*******************************************************************************
+ * Overlay the settings in the file, and then those of the named profile, on
+ * the config. If profileName is "", the file's DefaultProfile is used, if any.
+ */
+func (config *Config) LoadFile(path, profileName string) error {
+
+	var bytes, err = ioutil.ReadFile(path)
+	if err != nil { return err }
+	err = json.Unmarshal(bytes, config)
+	if err != nil { return fmt.Errorf("In config file %s: %s", path, err.Error()) }
+
+	var file configFile
+	err = json.Unmarshal(bytes, &file)
+	if err != nil { return fmt.Errorf("In config file %s: %s", path, err.Error()) }
+	if profileName == "" { profileName = file.DefaultProfile }
+	if profileName == "" { return nil }
+	var profile, found = file.Profiles[profileName]
+	if ! found {
+		return fmt.Errorf("Config file %s has no profile %s", path, profileName)
+	}
+	err = json.Unmarshal(profile, config)
+	if err != nil {
+		return fmt.Errorf("In profile %s of config file %s: %s", profileName, path, err.Error())
+	}
+	return nil
+}
+
+/*******************************************************************************
+ * Overlay the settings that are given by environment variables.
+ */
+func (config *Config) LoadEnv() error {
+	return forEachSetting(reflect.ValueOf(config).Elem(), "",
+		func(name string, field reflect.Value, tag reflect.StructTag) error {
+			var envName = tag.Get("env")
+			if envName == "" { return nil }
+			var value, found = os.LookupEnv(envName)
+			if ! found { return nil }
+			var err = setSetting(field, value)
+			if err != nil { return fmt.Errorf("In environment variable %s: %s", envName, err.Error()) }
+			return nil
+		})
+}
+
+/*******************************************************************************
+ * Replace each setting of the form "env:<name>" or "file:<path>" with the
+ * value of the environment variable, or the content of the file (without a
+ * trailing newline).
+ */
+func (config *Config) ResolveSecrets() {
+	forEachSetting(reflect.ValueOf(config).Elem(), "",
+		func(name string, field reflect.Value, tag reflect.StructTag) error {
+			if field.Kind() != reflect.String { return nil }
+			var value = field.String()
+			if strings.HasPrefix(value, "env:") {
+				var envName = strings.TrimPrefix(value, "env:")
+				var envValue, found = os.LookupEnv(envName)
+				if ! found {
+					fmt.Println(fmt.Sprintf("Warning: %s: environment variable %s is not set",
+						name, envName))
+				}
+				field.SetString(envValue)
+			} else if strings.HasPrefix(value, "file:") {
+				var bytes, err = ioutil.ReadFile(strings.TrimPrefix(value, "file:"))
+				if err != nil { fmt.Println(fmt.Sprintf("Warning: %s: %s", name, err.Error())) }
+				field.SetString(strings.TrimRight(string(bytes), "\r\n"))
+			}
+			return nil
+		})
+}
+
+/*******************************************************************************
+ * Print the settings. Secrets are masked.
+ */
+func (config *Config) Print() {
+	fmt.Println("Config:")
+	forEachSetting(reflect.ValueOf(config).Elem(), "",
+		func(name string, field reflect.Value, tag reflect.StructTag) error {
+			var value = fmt.Sprintf("%v", field.Interface())
+			if (tag.Get("secret") == "true") && (value != "") { value = "********" }
+			fmt.Println(fmt.Sprintf("\t%s: %s", name, value))
+			return nil
+		})
+}
+
+/*******************************************************************************
+ * Call f for each setting, i.e., each field that is not a struct, depth-first.
+ * name is the dotted path of the setting, e.g., "Registry.Host".
+ */
+func forEachSetting(value reflect.Value, prefix string,
+	f func(name string, field reflect.Value, tag reflect.StructTag) error) error {
+
+	for i := 0; i < value.NumField(); i++ {
+		var structField = value.Type().Field(i)
+		var field = value.Field(i)
+		var err error
+		if field.Kind() == reflect.Struct {
+			err = forEachSetting(field, prefix + structField.Name + ".", f)
+		} else {
+			err = f(prefix + structField.Name, field, structField.Tag)
+		}
+		if err != nil { return err }
+	}
+	return nil
+}
+
+/*******************************************************************************
+ * Set a string, int or bool setting from its string form.
+ */
+func setSetting(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		var n, err = strconv.Atoi(value)
+		if err != nil { return err }
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		var b, err = strconv.ParseBool(value)
+		if err != nil { return err }
+		field.SetBool(b)
+	default:
+		panic("Unsupported setting type: " + field.Kind().String())
+	}
+	return nil
+}
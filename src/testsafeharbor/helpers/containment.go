@@ -0,0 +1,129 @@
+/*******************************************************************************
+ * Containment of tests that hang or panic, so that one test cannot block or
+ * crash the whole run.
+ *
+ * Each test has a deadline, TestTimeout after StartTest (none if TestTimeout
+ * is 0); SetTestTimeout changes it for the current test. A request that is sent
+ * through the TestContext is abandoned, and fails the test, if the deadline
+ * passes before the response arrives. A test that passes its deadline for any
+ * other reason is failed when it ends.
+ *
+ * Suites run under RunProtected, which turns a panic - including that of
+ * AbortAllTests - into a failure of the current test, with the stack trace of
+ * the panic, so that the runner can go on to the next suite.
+ */
+
+package helpers
+
+import (
+	"fmt"
+	"errors"
+	"net/http"
+	"time"
+)
+
+/*******************************************************************************
+ * The value with which AbortAllTests panics. If stopping is true, tests are
+ * being stopped because of an earlier failure (see StopOnFirstError), and no
+ * further failure is recorded.
+ */
+type abortSignal struct {
+	msg string
+	stopping bool
+}
+
+/*******************************************************************************
+ * Set the deadline of the current test to the specified time after it started.
+ * A timeout of 0 removes the deadline.
+ */
+func (testContext *TestContext) SetTestTimeout(timeout time.Duration) {
+	if testContext.currentResult == nil { return }
+	testContext.testTimeout = timeout
+	if timeout == 0 {
+		testContext.testDeadline = time.Time{}
+	} else {
+		testContext.testDeadline = testContext.currentResult.StartTime.Add(timeout)
+	}
+}
+
+/*******************************************************************************
+ * Call send, unless the current test's deadline passes first, in which case
+ * fail the test and return an error. A response that arrives after the deadline
+ * is discarded.
+ */
+func (testContext *TestContext) sendBeforeDeadline(reqName string,
+	send func() (*http.Response, error)) (*http.Response, error) {
+
+	if testContext.testDeadline.IsZero() { return send() }
+	var remaining = time.Until(testContext.testDeadline)
+	if remaining <= 0 { return nil, testContext.failTimedOutRequest(reqName) }
+
+	type outcome struct {
+		resp *http.Response
+		err error
+	}
+	var done = make(chan outcome, 1)
+	go func() {
+		var resp, err = send()
+		done <- outcome{ resp, err }
+	}()
+	var timer = time.NewTimer(remaining)
+	defer timer.Stop()
+	select {
+	case result := <-done:
+		return result.resp, result.err
+	case <-timer.C:
+		go func() {
+			var result = <-done
+			if result.resp != nil { result.resp.Body.Close() }
+		}()
+		return nil, testContext.failTimedOutRequest(reqName)
+	}
+}
+
+func (testContext *TestContext) failTimedOutRequest(reqName string) error {
+	var msg = fmt.Sprintf("%s: test deadline of %s exceeded", reqName, testContext.testTimeout)
+	testContext.FailTestWithMessage(msg)
+	return errors.New(msg)
+}
+
+/*******************************************************************************
+ * Fail the result if its test ran past its deadline but has not failed.
+ */
+func (testContext *TestContext) checkDeadline(result *TestResult) {
+	if testContext.testDeadline.IsZero() { return }
+	if ! time.Now().After(testContext.testDeadline) { return }
+	if testContext.TestStatus[result.Key] == "Fail" { return }
+	var msg = fmt.Sprintf("Test exceeded its deadline of %s", testContext.testTimeout)
+	fmt.Println("Failed test", result.Key)
+	fmt.Println(msg)
+	testContext.NoOfTestsThatFailed++
+	testContext.TestStatus[result.Key] = "Fail"
+	result.Messages = append(result.Messages, msg)
+}
+
+/*******************************************************************************
+ * Call f. If f panics, fail the current test - or, if there is none, or it has
+ * already passed or failed, a new test named after the panic - with the stack
+ * trace of the panic, and return normally.
+ */
+func (testContext *TestContext) RunProtected(f func()) {
+	defer func() {
+		var r = recover()
+		if r == nil { return }
+		var kind, msg string
+		if signal, isType := r.(*abortSignal); isType {
+			fmt.Println("Aborting suite: " + signal.msg)
+			if signal.stopping { return }
+			kind, msg = "Abort", "Aborted: " + signal.msg
+		} else {
+			kind, msg = "Panic", fmt.Sprintf("Panic: %v", r)
+		}
+		if (testContext.currentResult == nil) ||
+			(testContext.TestStatus[testContext.testName] != "") {
+			testContext.startTest(kind)
+		}
+		testContext.FailTestWithMessage(msg)
+	}()
+	f()
+}
@@ -3,9 +3,14 @@ package helpers
 import (
 	"fmt"
 	"reflect"
-	//"strings"
+	"strings"
+	"io"
+	"testing/iotest"
 	//"errors"
 	"time"
+	"math"
+	"math/big"
+	"math/rand"
 	//"runtime/debug"
 	
 )
@@ -56,7 +61,7 @@ func (testContext *TestContext) TryJsonDeserByteArray(json string, expected []in
 	var value reflect.Value
 	var err error
 	var pos int = 0
-	value, err = parseJSON_array_value(json, &pos)
+	value, err = parseJSON_array_value(nil, "", json, &pos)
 	testContext.AssertErrIsNil(err, "")
 	testContext.AssertThat(value.IsValid(), "Value is not valid")
 	testContext.AssertThat(value.Len() == len(expected),
@@ -98,6 +103,100 @@ func (testContext *TestContext) TryJsonDeserTime(json string, expected time.Time
 	testContext.PassTestIfNoFailures()
 }
 
+/*******************************************************************************
+ * Parse a number, and check that it has the type and value of expected: an int,
+ * float64 or *big.Int.
+ */
+func (testContext *TestContext) TryJsonDeserNumber(json string, expected interface{}) {
+	
+	testContext.StartTest("TryJsonDeserNumber")
+
+	var value reflect.Value
+	var err error
+	var pos int = 0
+	value, err = parseJSON_simple_value(json, &pos)
+	if ! testContext.AssertErrIsNil(err, "") { return }
+	if ! testContext.AssertThat(value.IsValid(), "Value is not valid") { return }
+	testContext.AssertThat(value.Type() == reflect.TypeOf(expected),
+		"Type of value is " + value.Type().String() + ", expected " + reflect.TypeOf(expected).String())
+	if testContext.AssertThat(fmt.Sprint(value.Interface()) == fmt.Sprint(expected),
+		fmt.Sprintf("value: %v, expected: %v", value.Interface(), expected)) {
+		fmt.Println(fmt.Sprintf("Success: value=%v", value.Interface()))
+	}
+	testContext.PassTestIfNoFailures()
+}
+
+/*******************************************************************************
+ * 
+ */
+func (testContext *TestContext) TryJsonDeserNull() {
+	
+	testContext.StartTest("TryJsonDeserNull")
+
+	var values []reflect.Value
+	var err error
+	values, err = parseJSON("{\"Id\": null, \"Size\": 2}")
+	if ! testContext.AssertErrIsNil(err, "") { return }
+	if ! testContext.AssertThat(len(values) == 2,
+		fmt.Sprintf("%d values returned, expected 2", len(values))) { return }
+	testContext.AssertThat(parseJSON_isNull(values[0]), "First value is not null")
+	testContext.AssertThat(! parseJSON_isNull(values[1]), "Second value is null")
+	testContext.PassTestIfNoFailures()
+}
+
+/*******************************************************************************
+ * Reconstitute a GHI, with and without its optional fields, and check that a
+ * null field is passed to NewGHI as nil, and that numbers are converted to the
+ * types of NewGHI's parameters.
+ */
+func (testContext *TestContext) TryJsonDeserOptionalFields() {
+	testContext.StartTest("TryJsonDeserOptionalFields")
+
+	var client TestPersistClient = &InMemClient{}
+	var size, _ = new(big.Int).SetString("123456789012345678901234567890", 10)
+	var comment = "a comment"
+	var ghis = []GHI{
+		client.NewGHI(-2.5e-3, size, &comment,
+			client.NewABC(-7, "nested", []string{"gamma"}, false)),
+		client.NewGHI(3, nil, nil, nil),
+	}
+	
+	for _, ghi := range ghis {
+		var jsonString = ghi.toJSON()
+		fmt.Println("json: " + jsonString)
+		var obj interface{}
+		var err error
+		_, obj, err = ReconstituteObject(client, jsonString)
+		if ! testContext.AssertErrIsNil(err, "when reconstituting object") { return }
+		var ghi2 GHI
+		var isType bool
+		ghi2, isType = obj.(GHI)
+		if ! testContext.AssertThat(isType, "Object is not a GHI") { return }
+		
+		testContext.AssertThat(ghi2.getScore() == ghi.getScore(),
+			fmt.Sprintf("score is %v, expected %v", ghi2.getScore(), ghi.getScore()))
+		if ghi.getSize() == nil {
+			testContext.AssertThat(ghi2.getSize() == nil, "size is not nil")
+		} else if testContext.AssertThat(ghi2.getSize() != nil, "size is nil") {
+			testContext.AssertThat(ghi2.getSize().Cmp(ghi.getSize()) == 0,
+				"size is " + ghi2.getSize().String() + ", expected " + ghi.getSize().String())
+		}
+		if ghi.getComment() == nil {
+			testContext.AssertThat(ghi2.getComment() == nil, "comment is not nil")
+		} else if testContext.AssertThat(ghi2.getComment() != nil, "comment is nil") {
+			testContext.AssertThat(*ghi2.getComment() == *ghi.getComment(),
+				"comment is " + *ghi2.getComment() + ", expected " + *ghi.getComment())
+		}
+		if ghi.getABC() == nil {
+			testContext.AssertThat(ghi2.getABC() == nil, "abc is not nil")
+		} else if testContext.AssertThat(ghi2.getABC() != nil, "abc is nil") {
+			testContext.AssertThat(ghi2.getABC().getA() == ghi.getABC().getA(),
+				fmt.Sprintf("abc.a is %d, expected %d", ghi2.getABC().getA(), ghi.getABC().getA()))
+		}
+	}
+	testContext.PassTestIfNoFailures()
+}
+
 /*******************************************************************************
  * 
  */
@@ -144,43 +243,435 @@ func (testContext *TestContext) TryJsonDeserNestedType() {
 	testContext.StartTest("TryJsonDeserNestedType")
 
 	var client TestPersistClient = &InMemClient{}
-	var def = &InMemDEF{
-		ABC: client.NewABC(123, "this is a string", []string{"alpha", "beta"}, true),
-		xyz: 456,
-	}
+	var def = client.NewDEF(
+		client.NewABC(123, "this is a string", []string{"alpha", "beta"}, true), 456)
 	var jsonString = def.toJSON()
+	fmt.Println("json: " + jsonString)
 
 	var typeName string
-	var remainder string
+	var obj interface{}
 	var err error
-	typeName, remainder, err = retrieveTypeName(jsonString)
-	testContext.AssertErrIsNil(err, "when retrieving type name")
+	typeName, obj, err = ReconstituteObject(client, jsonString)
+	if ! testContext.AssertErrIsNil(err, "when reconstituting object") { return }
+	testContext.AssertThat(typeName == "DEF", "Type name is " + typeName + ", expected DEF")
 	
-	var methodName = "New" + typeName
-	var method = reflect.ValueOf(client).MethodByName(methodName)
-	if ! testContext.AssertThat(method.IsValid(),
-		"Method with name " + methodName + " not found") { return }
-	var argAr []reflect.Value
-	argAr, err = parseJSON(remainder)
-	testContext.AssertErrIsNil(err, "when calling parseJSON")
-	fmt.Println("argAr has " + fmt.Sprintf("%d", len(argAr)) + " elements")
-
-	var retValues []reflect.Value = method.Call(argAr)
-	var retValue0 interface{} = retValues[0].Interface()
 	var def2 DEF
 	var isType bool
-	def2, isType = retValue0.(DEF)
-	if !isType { fmt.Println("def2 is NOT an DEF") } else {
-		fmt.Println("def2 IS a DEF")
-		fmt.Println(fmt.Sprintf("\tdef2.a=%d", def2.getA()))
-		fmt.Println("\tdef2.bs=" + def2.getBs())
-		//fmt.Println("\tabc2.Car=" + string(abc2.getCar()))
-		//fmt.Println("\tabc2.db=" + string(abc2.getDb()))
-		fmt.Println(fmt.Sprintf("\tdef2.xyz=%d", def2.getXyz()))
+	def2, isType = obj.(DEF)
+	if ! testContext.AssertThat(isType, "def2 is NOT a DEF") { return }
+	fmt.Println("def2 IS a DEF")
+	fmt.Println(fmt.Sprintf("\tdef2.a=%d", def2.getA()))
+	fmt.Println("\tdef2.bs=" + def2.getBs())
+	fmt.Println(fmt.Sprintf("\tdef2.xyz=%d", def2.getXyz()))
+	testContext.AssertThat(def2.getA() == 123, fmt.Sprintf("def2.a is %d, expected 123", def2.getA()))
+	testContext.AssertThat(def2.getBs() == "this is a string",
+		"def2.bs is " + def2.getBs() + ", expected this is a string")
+	testContext.AssertThat(len(def2.getCar()) == 2,
+		fmt.Sprintf("def2.car has %d elements, expected 2", len(def2.getCar())))
+	testContext.AssertThat(def2.getDb(), "def2.db is false, expected true")
+	testContext.AssertThat(def2.getXyz() == 456, fmt.Sprintf("def2.xyz is %d, expected 456", def2.getXyz()))
+	testContext.PassTestIfNoFailures()
+}
+
+/*******************************************************************************
+ * Reconstitute an ABC whose fields are in a different order than the
+ * parameters of NewABC, and a DEF whose optional field is omitted.
+ */
+func (testContext *TestContext) TryJsonDeserBinding() {
+	testContext.StartTest("TryJsonDeserBinding")
+
+	var client TestPersistClient = &InMemClient{}
+	var obj interface{}
+	var err error
+	_, obj, err = ReconstituteObject(client,
+		"\"ABC\": {\"db\": true, \"car\": [\"alpha\"], \"bs\": \"reordered\", \"a\": 7}")
+	if testContext.AssertErrIsNil(err, "when reconstituting reordered ABC") {
+		var abc, isType = obj.(ABC)
+		if testContext.AssertThat(isType, "Object is not an ABC") {
+			testContext.AssertThat(abc.getA() == 7, fmt.Sprintf("a is %d, expected 7", abc.getA()))
+			testContext.AssertThat(abc.getBs() == "reordered", "bs is " + abc.getBs())
+			testContext.AssertThat(abc.getDb(), "db is false, expected true")
+		}
+	}
+	
+	_, obj, err = ReconstituteObject(client,
+		"\"DEF\": {\"ABC\": {\"a\": 1, \"bs\": \"\", \"car\": [\"x\"], \"db\": false}}")
+	if testContext.AssertErrIsNil(err, "when reconstituting DEF without xyz") {
+		var def, isType = obj.(DEF)
+		if testContext.AssertThat(isType, "Object is not a DEF") {
+			testContext.AssertThat(def.getXyz() == 0, fmt.Sprintf("xyz is %d, expected 0", def.getXyz()))
+		}
 	}
 	testContext.PassTestIfNoFailures()
 }
 
+/*******************************************************************************
+ * Reconstitute an object whose fields do not match the registered parameters
+ * of its New method, and check that the BindingError reports the missing,
+ * extra and mismatched fields.
+ */
+func (testContext *TestContext) TryJsonDeserBindingError(json string, missing, extra,
+	mismatched []string) {
+	testContext.StartTest("TryJsonDeserBindingError")
+
+	var client TestPersistClient = &InMemClient{}
+	var err error
+	_, _, err = ReconstituteObject(client, json)
+	if ! testContext.AssertThat(err != nil, "No error returned") { return }
+	fmt.Println("Error: " + err.Error())
+	var bindingError, isType = err.(*BindingError)
+	if ! testContext.AssertThat(isType, "Error is not a BindingError") { return }
+	
+	var mismatchedFields = []string{}
+	for _, mismatch := range bindingError.Mismatched {
+		mismatchedFields = append(mismatchedFields, mismatch.Field)
+	}
+	testContext.AssertThat(reflect.DeepEqual(bindingError.Missing, missing),
+		fmt.Sprintf("Missing fields are %v, expected %v", bindingError.Missing, missing))
+	testContext.AssertThat(reflect.DeepEqual(bindingError.Extra, extra),
+		fmt.Sprintf("Extra fields are %v, expected %v", bindingError.Extra, extra))
+	testContext.AssertThat(reflect.DeepEqual(mismatchedFields, mismatched),
+		fmt.Sprintf("Mismatched fields are %v, expected %v", mismatchedFields, mismatched))
+	testContext.PassTestIfNoFailures()
+}
+
+/*******************************************************************************
+ * Parse an array whose elements are of different types, and check that it is
+ * returned as an []interface{} with the expected elements.
+ */
+func (testContext *TestContext) TryJsonDeserMixedArray(json string, expected []interface{}) {
+	
+	testContext.StartTest("TryJsonDeserMixedArray")
+
+	var value reflect.Value
+	var err error
+	var pos int = 0
+	value, err = parseJSON_array_value(nil, "", json, &pos)
+	if ! testContext.AssertErrIsNil(err, "") { return }
+	if ! testContext.AssertThat(value.IsValid(), "Value is not valid") { return }
+	var elements, isType = value.Interface().([]interface{})
+	if ! testContext.AssertThat(isType, "Value is a " + value.Type().String() +
+		", expected []interface{}") { return }
+	testContext.AssertThat(reflect.DeepEqual(elements, expected),
+		fmt.Sprintf("value: %#v, expected: %#v", elements, expected))
+	testContext.PassTestIfNoFailures()
+}
+
+/*******************************************************************************
+ * Reconstitute a JKL, whose arrays are converted to the element types of
+ * NewJKL's parameters, and an ABC with an empty array.
+ */
+func (testContext *TestContext) TryJsonDeserArrays() {
+	testContext.StartTest("TryJsonDeserArrays")
+
+	var client TestPersistClient = &InMemClient{}
+	var json = "\"JKL\": {" +
+		"\"ABC\": [{\"a\": 1, \"bs\": \"one\", \"car\": [], \"db\": true}, " +
+			"{\"a\": 2, \"bs\": \"two\", \"car\": [\"x\", \"y\"], \"db\": false}], " +
+		"\"mixed\": [1, \"two\", true, null, [3]], " +
+		"\"scores\": [1, 2.5, -3]}"
+	var obj interface{}
+	var err error
+	_, obj, err = ReconstituteObject(client, json)
+	if ! testContext.AssertErrIsNil(err, "when reconstituting JKL") { return }
+	var jkl, isType = obj.(JKL)
+	if ! testContext.AssertThat(isType, "Object is not a JKL") { return }
+	
+	if testContext.AssertThat(len(jkl.getABCs()) == 2,
+		fmt.Sprintf("%d ABCs, expected 2", len(jkl.getABCs()))) {
+		testContext.AssertThat(jkl.getABCs()[1].getA() == 2,
+			fmt.Sprintf("ABC[1].a is %d, expected 2", jkl.getABCs()[1].getA()))
+		testContext.AssertThat(len(jkl.getABCs()[0].getCar()) == 0, "ABC[0].car is not empty")
+		testContext.AssertThat(reflect.DeepEqual(jkl.getABCs()[1].getCar(), []string{"x", "y"}),
+			fmt.Sprintf("ABC[1].car is %v", jkl.getABCs()[1].getCar()))
+	}
+	var expectedMixed = []interface{}{ 1, "two", true, nil, []int{3} }
+	testContext.AssertThat(reflect.DeepEqual(jkl.getMixed(), expectedMixed),
+		fmt.Sprintf("mixed is %#v, expected %#v", jkl.getMixed(), expectedMixed))
+	testContext.AssertThat(reflect.DeepEqual(jkl.getScores(), []float64{ 1, 2.5, -3 }),
+		fmt.Sprintf("scores is %v", jkl.getScores()))
+	testContext.PassTestIfNoFailures()
+}
+
+/*******************************************************************************
+ * Serialize randomly generated objects of each persisted type, reconstitute
+ * them, and check that each reconstituted object serializes to the same JSON
+ * as the original. The seed is printed, so that a failure can be reproduced.
+ */
+func (testContext *TestContext) TryJsonRoundTrip(count int, seed int64) {
+	testContext.StartTest("TryJsonRoundTrip")
+
+	fmt.Println(fmt.Sprintf("Seed: %d", seed))
+	var random = rand.New(rand.NewSource(seed))
+	var client TestPersistClient = &InMemClient{}
+	for i := 0; i < count; i++ {
+		var original = randomPersistable(client, random, i)
+		var jsonString = original.toJSON()
+		
+		var obj interface{}
+		var err error
+		_, obj, err = ReconstituteObject(client, jsonString)
+		if ! testContext.AssertErrIsNil(err, "when reconstituting " + jsonString) { break }
+		var reconstituted, isType = obj.(Persistable)
+		if ! testContext.AssertThat(isType, "Reconstituted object has no toJSON method") { break }
+		if ! testContext.AssertThat(reconstituted.toJSON() == jsonString,
+			"Round trip changed the object:\n\t" + jsonString + "\n\t" + reconstituted.toJSON()) { break }
+	}
+	testContext.PassTestIfNoFailures()
+}
+
+/*******************************************************************************
+ * Return a randomly generated object of one of the persisted types, according
+ * to i.
+ */
+func randomPersistable(client TestPersistClient, random *rand.Rand, i int) Persistable {
+	switch i % 4 {
+	case 0: return randomABC(client, random)
+	case 1: return randomDEF(client, random)
+	case 2: return randomGHI(client, random)
+	default: return randomJKL(client, random)
+	}
+}
+
+func randomABC(client TestPersistClient, random *rand.Rand) ABC {
+	var car []string
+	if random.Intn(4) > 0 {
+		car = make([]string, random.Intn(4))
+		for i, _ := range car { car[i] = randomString(random) }
+	}
+	return client.NewABC(int(random.Int63()) - int(random.Int63()), randomString(random),
+		car, random.Intn(2) == 0)
+}
+
+/*******************************************************************************
+ * Return a DEF whose ABC is, sometimes, itself a DEF - which is written under
+ * its own type name, as is a DEF in the ABC of a GHI or a JKL.
+ */
+func randomDEF(client TestPersistClient, random *rand.Rand) DEF {
+	var abc ABC
+	if random.Intn(3) == 0 {
+		abc = randomDEF(client, random)
+	} else {
+		abc = randomABC(client, random)
+	}
+	return client.NewDEF(abc, random.Intn(2000) - 1000)
+}
+
+func randomGHI(client TestPersistClient, random *rand.Rand) GHI {
+	var size *big.Int
+	if random.Intn(3) > 0 {
+		size = new(big.Int).Lsh(big.NewInt(random.Int63()), uint(random.Intn(100)))
+		if random.Intn(2) == 0 { size.Neg(size) }
+	}
+	var comment *string
+	if random.Intn(3) > 0 {
+		var s = randomString(random)
+		comment = &s
+	}
+	var abc ABC
+	switch random.Intn(4) {
+	case 1, 2: abc = randomABC(client, random)
+	case 3: abc = randomDEF(client, random)
+	}
+	return client.NewGHI(randomFloat(random), size, comment, abc)
+}
+
+func randomJKL(client TestPersistClient, random *rand.Rand) JKL {
+	var abcs = make([]ABC, random.Intn(3))
+	var defs = random.Intn(3) == 0  // the objects of an array are all of one type
+	for i, _ := range abcs {
+		if defs { abcs[i] = randomDEF(client, random) } else { abcs[i] = randomABC(client, random) }
+	}
+	var mixed []interface{}
+	if random.Intn(4) > 0 {
+		mixed = make([]interface{}, random.Intn(5))
+		for i, _ := range mixed {
+			switch random.Intn(5) {
+			case 0: mixed[i] = random.Intn(1000) - 500
+			case 1: mixed[i] = float64(random.Intn(1000)) + 0.5
+			case 2: mixed[i] = randomString(random)
+			case 3: mixed[i] = random.Intn(2) == 0
+			case 4: mixed[i] = nil
+			}
+		}
+	}
+	var scores = make([]float64, random.Intn(4))
+	for i, _ := range scores { scores[i] = randomFloat(random) }
+	var created = time.Unix(random.Int63n(1e10), random.Int63n(1e9)).UTC()
+	return client.NewJKL(abcs, mixed, scores, created)
+}
+
+/*******************************************************************************
+ * Return a random finite float64, of any magnitude.
+ */
+func randomFloat(random *rand.Rand) float64 {
+	switch random.Intn(3) {
+	case 0: return float64(random.Intn(1000) - 500)
+	case 1: return random.NormFloat64()
+	default: return math.Ldexp(random.Float64() - 0.5, random.Intn(2000) - 1000)
+	}
+}
+
+/*******************************************************************************
+ * Return a random string, which may contain characters that must be escaped.
+ */
+func randomString(random *rand.Rand) string {
+	var chars = []rune("aZ0 _-\"\\/\n\r\t\b\f\x00\x1f{}[]:,\u00e9\u4e16\U0001F600")
+	var runes = make([]rune, random.Intn(12))
+	for i, _ := range runes { runes[i] = chars[random.Intn(len(chars))] }
+	return string(runes)
+}
+
+/*******************************************************************************
+ * Reconstitute malformed JSON, and check that a SyntaxError is returned, at
+ * the expected line and column, expecting the expected tokens.
+ */
+func (testContext *TestContext) TryJsonDeserSyntaxError(json string, line, column int,
+	expected []string) {
+	testContext.StartTest("TryJsonDeserSyntaxError")
+
+	var client TestPersistClient = &InMemClient{}
+	var err error
+	_, _, err = ReconstituteObject(client, json)
+	if ! testContext.AssertThat(err != nil, "No error returned") { return }
+	fmt.Println("Error: " + err.Error())
+	var syntaxError, isType = err.(*SyntaxError)
+	if ! testContext.AssertThat(isType, "Error is not a SyntaxError") { return }
+	testContext.AssertThat((syntaxError.Line == line) && (syntaxError.Column == column),
+		fmt.Sprintf("Error is at line %d, column %d, expected line %d, column %d",
+			syntaxError.Line, syntaxError.Column, line, column))
+	testContext.AssertThat(reflect.DeepEqual(syntaxError.Expected, expected),
+		fmt.Sprintf("Expected tokens are %v, expected %v", syntaxError.Expected, expected))
+	testContext.AssertThat(! strings.Contains(syntaxError.Snippet, "\n\t\n"),
+		"Snippet has no line for the caret")
+	testContext.PassTestIfNoFailures()
+}
+
+/*******************************************************************************
+ * Decode a stream of randomly generated records, one byte at a time, and check
+ * that each object is the one that was written. Then decode a stream with a
+ * malformed record, and check the position of the error in the stream.
+ */
+func (testContext *TestContext) TryJsonRecordDecoder(count int, seed int64) {
+	testContext.StartTest("TryJsonRecordDecoder")
+
+	fmt.Println(fmt.Sprintf("Seed: %d", seed))
+	var client TestPersistClient = &InMemClient{}
+	var records = randomRecords(client, rand.New(rand.NewSource(seed)), count)
+	var stream = strings.Join(records, ",\n")
+	
+	var decoder = NewRecordDecoder(client, iotest.OneByteReader(strings.NewReader(stream)))
+	for i := 0; ; i++ {
+		var obj, err = decodeNext(decoder)
+		if err == io.EOF {
+			testContext.AssertThat(i == count, fmt.Sprintf("Decoded %d objects, expected %d", i, count))
+			break
+		}
+		if ! testContext.AssertErrIsNil(err, fmt.Sprintf("when decoding object %d", i)) { break }
+		if ! testContext.AssertThat(i < count, "More objects decoded than were written") { break }
+		if ! testContext.AssertThat(obj.toJSON() == records[i],
+			fmt.Sprintf("Object %d is:\n\t%s\nexpected:\n\t%s", i, obj.toJSON(), records[i])) { break }
+	}
+	
+	stream = records[0] + "\n" + records[1] + "\n  \"ABC\": {\"a\": 1 \"bs\": \"x\"}\n" + records[2]
+	decoder = NewRecordDecoder(client, strings.NewReader(stream))
+	var err error
+	for i := 0; i < 3; i++ {
+		_, err = decodeNext(decoder)
+		if err != nil { break }
+	}
+	if testContext.AssertThat(err != nil, "No error for the malformed record") {
+		fmt.Println("Error: " + err.Error())
+		var syntaxError, isType = err.(*SyntaxError)
+		if testContext.AssertThat(isType, "Error is not a SyntaxError") {
+			testContext.AssertThat((syntaxError.Line == 3) && (syntaxError.Column == 18),
+				fmt.Sprintf("Error is at line %d, column %d, expected line 3, column 18",
+					syntaxError.Line, syntaxError.Column))
+		}
+	}
+	testContext.PassTestIfNoFailures()
+}
+
+func decodeNext(decoder *RecordDecoder) (Persistable, error) {
+	var _, obj, err = decoder.Decode()
+	if err != nil { return nil, err }
+	return obj.(Persistable), nil
+}
+
+/*******************************************************************************
+ * Reconstitute objects with no target, by their registered factories, and as
+ * registered interfaces: a DEF as an ABC, which it is, and a GHI as an ABC,
+ * which it is not. Check that an unknown type is reported with the known types.
+ */
+func (testContext *TestContext) TryJsonTypeRegistry() {
+	testContext.StartTest("TryJsonTypeRegistry")
+
+	var known = KnownTypes()
+	var isKnown = make(map[string]bool)
+	for _, typeName := range known { isKnown[typeName] = true }
+	for _, typeName := range []string{ "ABC", "DEF", "GHI", "JKL" } {
+		testContext.AssertThat(isKnown[typeName],
+			fmt.Sprintf("Type %s is not among the known types %v", typeName, known))
+	}
+	testContext.AssertThat(IsA("DEF", "ABC"), "A DEF is not an ABC")
+	testContext.AssertThat(! IsA("ABC", "DEF"), "An ABC is a DEF")
+	testContext.AssertThat(! IsA("GHI", "ABC"), "A GHI is an ABC")
+
+	var defJSON = `"DEF": {"ABC": {"a": 1, "bs": "x", "car": ["y"], "db": true}, "xyz": 2}`
+	var typeName, obj, err = ReconstituteAs(nil, defJSON, "ABC")
+	if testContext.AssertErrIsNil(err, "when reconstituting a DEF as an ABC") {
+		var abc, isType = obj.(ABC)
+		if testContext.AssertThat(isType, fmt.Sprintf("%T is not an ABC", obj)) {
+			testContext.AssertThat((typeName == "DEF") && (abc.getA() == 1) && (abc.getBs() == "x"),
+				"Reconstituted " + typeName + " " + abc.toJSON())
+		}
+	}
+
+	var abc = factoryClient.NewABC(1, "x", nil, false)
+	var nested = factoryClient.NewDEF(factoryClient.NewDEF(abc, 5), 7)
+	typeName, obj, err = ReconstituteObject(nil, nested.toJSON())
+	if testContext.AssertErrIsNil(err, "when reconstituting " + nested.toJSON()) {
+		var def, isType = obj.(*InMemDEF)
+		if testContext.AssertThat(isType, fmt.Sprintf("%T is not an *InMemDEF", obj)) {
+			var inner, isDEF = def.ABC.(DEF)
+			testContext.AssertThat(isDEF && (inner.getXyz() == 5) && (def.getXyz() == 7),
+				"Reconstituted " + def.toJSON() + " from " + nested.toJSON())
+		}
+	}
+	var mixed = factoryClient.NewJKL([]ABC{ abc, nested }, nil, nil, time.Time{})
+	_, err = ToJSON("JKL", mixed)
+	testContext.AssertThat(err != nil, "No error for an array of an ABC and a DEF")
+
+	_, _, err = ReconstituteAs(nil, `"GHI": {"score": 1.5}`, "ABC")
+	testContext.AssertThat(err != nil, "A GHI was reconstituted as an ABC")
+
+	_, _, err = ReconstituteObject(nil, `"XYZ": {"a": 1}`)
+	if testContext.AssertThat(err != nil, "No error for an unknown type") {
+		fmt.Println("Error: " + err.Error())
+		var unknownTypeError, isType = err.(*UnknownTypeError)
+		if testContext.AssertThat(isType, "Error is not an UnknownTypeError") {
+			testContext.AssertThat((unknownTypeError.TypeName == "XYZ") &&
+				reflect.DeepEqual(unknownTypeError.KnownTypes, known),
+				fmt.Sprintf("Unknown type %s, with known types %v",
+					unknownTypeError.TypeName, unknownTypeError.KnownTypes))
+		}
+	}
+
+	_, _, err = ReconstituteObject(nil, `"DEF": {"ABC": {"a": 1, "bs": "x", "car": ["y"], "db": true, "XYZ": {"q": 1}}}`)
+	var _, isType = err.(*UnknownTypeError)
+	testContext.AssertThat(isType, fmt.Sprintf("Error for a nested unknown type is %v", err))
+	testContext.PassTestIfNoFailures()
+}
+
+/*******************************************************************************
+ * Return the JSON of randomly generated objects of each persisted type.
+ */
+func randomRecords(client TestPersistClient, random *rand.Rand, count int) []string {
+	var records = make([]string, count)
+	for i, _ := range records { records[i] = randomPersistable(client, random, i).toJSON() }
+	return records
+}
+
 /*******************************************************************************
  * 
  */
@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+/*******************************************************************************
+ * The conformance tests for implementations of TestPersistClient. Each test
+ * is named after the client type, e.g., TryPersistStoreLoad(*helpers.RedisClient).
+ */
+
+/*******************************************************************************
+ * Store randomly generated objects of each persisted type, and check that each
+ * is loaded as it was stored.
+ */
+func (testContext *TestContext) TryPersistStoreLoad(client TestPersistClient, seed int64) {
+	testContext.StartTest(fmt.Sprintf("TryPersistStoreLoad(%T)", client))
+
+	fmt.Println(fmt.Sprintf("Seed: %d", seed))
+	var random = rand.New(rand.NewSource(seed))
+	var ids = make(map[string]string)  // JSON of each object, by ID
+	for i := 0; i < 20; i++ {
+		var obj = randomPersistable(client, random, i)
+		var id, err = client.Store(obj)
+		if ! testContext.AssertErrIsNil(err, "when storing " + obj.toJSON()) { return }
+		if ! testContext.AssertThat(ids[id] == "", "ID " + id + " was allocated twice") { return }
+		ids[id] = obj.toJSON()
+	}
+
+	for id, expected := range ids {
+		var obj, err = client.Load(id)
+		if ! testContext.AssertErrIsNil(err, "when loading " + id) { continue }
+		testContext.AssertThat(toJSONOf(obj) == expected,
+			fmt.Sprintf("Object %s is:\n\t%s\nexpected:\n\t%s", id, toJSONOf(obj), expected))
+	}
+	testContext.PassTestIfNoFailures()
+}
+
+/*******************************************************************************
+ * Replace a stored object with one of another type, and check that the new
+ * object is loaded. Check that an object that was never stored cannot be updated.
+ */
+func (testContext *TestContext) TryPersistUpdate(client TestPersistClient) {
+	testContext.StartTest(fmt.Sprintf("TryPersistUpdate(%T)", client))
+
+	var abc = client.NewABC(1, "before", []string{"x"}, true)
+	var id, err = client.Store(abc)
+	if ! testContext.AssertErrIsNil(err, "when storing") { return }
+
+	var def = client.NewDEF(client.NewABC(2, "after", []string{"y", "z"}, false), 3)
+	err = client.Update(id, def)
+	if ! testContext.AssertErrIsNil(err, "when updating " + id) { return }
+
+	var obj interface{}
+	obj, err = client.Load(id)
+	if testContext.AssertErrIsNil(err, "when loading " + id) {
+		testContext.AssertThat(toJSONOf(obj) == def.toJSON(),
+			"Loaded " + toJSONOf(obj) + ", expected " + def.toJSON())
+	}
+
+	err = client.Update("no-such-id", def)
+	testContext.AssertThat(err == ErrObjectNotFound,
+		fmt.Sprintf("Update of a nonexistent object returned %v, expected ErrObjectNotFound", err))
+	testContext.PassTestIfNoFailures()
+}
+
+/*******************************************************************************
+ * Delete a stored object, and check that it can no longer be loaded, updated
+ * or deleted, and that other objects are not affected.
+ */
+func (testContext *TestContext) TryPersistDelete(client TestPersistClient) {
+	testContext.StartTest(fmt.Sprintf("TryPersistDelete(%T)", client))
+
+	var abc1 = client.NewABC(1, "deleted", nil, true)
+	var abc2 = client.NewABC(2, "kept", nil, true)
+	var id1, err = client.Store(abc1)
+	if ! testContext.AssertErrIsNil(err, "when storing") { return }
+	var id2 string
+	id2, err = client.Store(abc2)
+	if ! testContext.AssertErrIsNil(err, "when storing") { return }
+
+	err = client.Delete(id1)
+	if ! testContext.AssertErrIsNil(err, "when deleting " + id1) { return }
+
+	_, err = client.Load(id1)
+	testContext.AssertThat(err == ErrObjectNotFound,
+		fmt.Sprintf("Load of a deleted object returned %v, expected ErrObjectNotFound", err))
+	err = client.Update(id1, abc1)
+	testContext.AssertThat(err == ErrObjectNotFound,
+		fmt.Sprintf("Update of a deleted object returned %v, expected ErrObjectNotFound", err))
+	err = client.Delete(id1)
+	testContext.AssertThat(err == ErrObjectNotFound,
+		fmt.Sprintf("Second delete returned %v, expected ErrObjectNotFound", err))
+
+	var obj interface{}
+	obj, err = client.Load(id2)
+	if testContext.AssertErrIsNil(err, "when loading " + id2) {
+		testContext.AssertThat(toJSONOf(obj) == abc2.toJSON(),
+			"Loaded " + toJSONOf(obj) + ", expected " + abc2.toJSON())
+	}
+	testContext.PassTestIfNoFailures()
+}
+
+func toJSONOf(obj interface{}) string {
+	var persistable, isType = obj.(Persistable)
+	if ! isType { return fmt.Sprintf("<%T, which is not Persistable>", obj) }
+	return persistable.toJSON()
+}
@@ -33,6 +33,7 @@ func (testContext *TestContext) TryRedisSetGetString(redisClient redis.Client) {
 	value, err = redisClient.Get("abc")
 	if ! testContext.AssertErrIsNil(err, "When getting value") { return }
 	fmt.Println("Retrieved value", string(value))
+	testContext.PassTestIfNoFailures()
 }
 
 /*******************************************************************************
@@ -78,7 +79,7 @@ func (testContext *TestContext) TryRedisGetJSONObject(redisClient redis.Client)
 		fmt.Println("typeName=" + typeName)
 	}
 	if ! testContext.AssertThat(reconstitutedABC.getA() == 123, "Wrong value for abc") {
-		fmt.Println("obj.getABC()=" + string(reconstitutedABC.getA()))
+		fmt.Println(fmt.Sprintf("obj.getABC()=%d", reconstitutedABC.getA()))
 	}
 	
 	testContext.PassTestIfNoFailures()
@@ -0,0 +1,178 @@
+/*******************************************************************************
+ * A registry of test suites. Each suite declares the suites that it depends on,
+ * tags that describe what it needs (e.g., "needs-docker"), and optional setup
+ * and teardown functions. The runner selects suites by name and by tag, and runs
+ * them in an order that honors the declared dependencies.
+ */
+
+package helpers
+
+import (
+	"fmt"
+	"errors"
+	"strings"
+)
+
+type TestSuite struct {
+	Name string
+	Function func(*TestContext)
+	DependsOn []string  // suites that must run before this one, if selected
+	Tags []string
+	Setup func(*TestContext)  // optional
+	Teardown func(*TestContext)  // optional; called even if the suite panics
+}
+
+type SuiteRegistry struct {
+	suites []*TestSuite  // in registration order
+	suitesByName map[string]*TestSuite
+}
+
+func NewSuiteRegistry() *SuiteRegistry {
+	return &SuiteRegistry{
+		suites: make([]*TestSuite, 0),
+		suitesByName: make(map[string]*TestSuite),
+	}
+}
+
+/*******************************************************************************
+ * Add a suite to the registry. Suite names must be unique.
+ */
+func (registry *SuiteRegistry) Register(suite *TestSuite) {
+	if registry.suitesByName[suite.Name] != nil {
+		panic("Test suite " + suite.Name + " registered more than once")
+	}
+	registry.suites = append(registry.suites, suite)
+	registry.suitesByName[suite.Name] = suite
+}
+
+/*******************************************************************************
+ * Return the suite with the specified name, or nil if there is none.
+ */
+func (registry *SuiteRegistry) GetSuite(name string) *TestSuite {
+	return registry.suitesByName[name]
+}
+
+/*******************************************************************************
+ * Return all of the registered suites, in registration order.
+ */
+func (registry *SuiteRegistry) GetSuites() []*TestSuite {
+	return registry.suites
+}
+
+/*******************************************************************************
+ * Return the suites that are named (or all suites, if names is empty), less
+ * those that do not have any of the tags in tags (if tags is not empty), and
+ * less those that have any of the tags in skipTags. The suites are returned
+ * sorted such that each suite follows the selected suites that it depends on.
+ * Dependencies that are not selected are not added: a dependency only
+ * constrains the order in which suites are run. A suite that is named more
+ * than once is selected once.
+ */
+func (registry *SuiteRegistry) Select(names []string, tags []string,
+	skipTags []string) ([]*TestSuite, error) {
+
+	var candidates []*TestSuite
+	if len(names) == 0 {
+		candidates = registry.suites
+	} else {
+		candidates = make([]*TestSuite, 0)
+		var isNamed = make(map[string]bool)
+		for _, name := range names {
+			var suite = registry.suitesByName[name]
+			if suite == nil { return nil, errors.New("Test '" + name + "' not recognized") }
+			if isNamed[name] { continue }
+			isNamed[name] = true
+			candidates = append(candidates, suite)
+		}
+	}
+
+	var selected = make([]*TestSuite, 0)
+	for _, suite := range candidates {
+		if (len(tags) > 0) && (! suite.HasAnyTag(tags)) { continue }
+		if suite.HasAnyTag(skipTags) { continue }
+		selected = append(selected, suite)
+	}
+
+	return registry.Sort(selected)
+}
+
+/*******************************************************************************
+ * Sort the specified suites topologically, according to their dependencies on
+ * each other. Among suites whose dependencies have been satisfied, the order in
+ * which the suites are given is preserved. Return an error if a suite is given
+ * more than once, if a suite depends on a suite that is not registered, or if
+ * there is a dependency cycle.
+ */
+func (registry *SuiteRegistry) Sort(suites []*TestSuite) ([]*TestSuite, error) {
+
+	var isSelected = make(map[string]bool)
+	for _, suite := range suites {
+		if isSelected[suite.Name] { return nil, errors.New(
+			"Test suite " + suite.Name + " is given more than once") }
+		isSelected[suite.Name] = true
+		for _, dep := range suite.DependsOn {
+			if registry.suitesByName[dep] == nil {
+				return nil, errors.New(fmt.Sprintf(
+					"Test suite %s depends on unknown suite %s", suite.Name, dep))
+			}
+		}
+	}
+
+	var sorted = make([]*TestSuite, 0, len(suites))
+	var isDone = make(map[string]bool)
+	for len(sorted) < len(suites) {
+		var progressed = false
+		for _, suite := range suites {
+			if isDone[suite.Name] { continue }
+			var ready = true
+			for _, dep := range suite.DependsOn {
+				if isSelected[dep] && (! isDone[dep]) { ready = false; break }
+			}
+			if ! ready { continue }
+			sorted = append(sorted, suite)
+			isDone[suite.Name] = true
+			progressed = true
+			break  // rescan from the start, to preserve the given order
+		}
+		if ! progressed {
+			var remaining = []string{}
+			for _, suite := range suites {
+				if ! isDone[suite.Name] { remaining = append(remaining, suite.Name) }
+			}
+			return nil, errors.New("Dependency cycle among test suites: " +
+				strings.Join(remaining, ", "))
+		}
+	}
+	return sorted, nil
+}
+
+/*******************************************************************************
+ * Return true if the suite has any of the specified tags.
+ */
+func (suite *TestSuite) HasAnyTag(tags []string) bool {
+	for _, tag := range tags {
+		if ContainsString(suite.Tags, tag) { return true }
+	}
+	return false
+}
+
+/*******************************************************************************
+ * Run the suite's setup, the suite, and then the suite's teardown. A panic in
+ * the suite fails the current test, and the teardown is still performed. If
+ * the context stops on the first error and a test has failed, the suite is
+ * skipped.
+ */
+func (suite *TestSuite) Run(testContext *TestContext) {
+	if testContext.StopOnFirstError && (testContext.NoOfTestsThatFailed > 0) {
+		fmt.Println("Skipping test suite " + suite.Name + ", because a test has failed")
+		return
+	}
+	testContext.RunProtected(func() {
+		testContext.StartSuite(suite.Name)
+		if suite.Setup != nil { suite.Setup(testContext) }
+		suite.Function(testContext)
+	})
+	if suite.Teardown != nil {
+		testContext.RunProtected(func() { suite.Teardown(testContext) })
+	}
+}
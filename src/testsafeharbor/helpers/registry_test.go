@@ -0,0 +1,43 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestRegistry() *SuiteRegistry {
+	var registry = NewSuiteRegistry()
+	var noop = func(testContext *TestContext) {}
+	registry.Register(&TestSuite{ Name: "Step1", Function: noop })
+	registry.Register(&TestSuite{ Name: "Step2", Function: noop, DependsOn: []string{"Step1"} })
+	registry.Register(&TestSuite{ Name: "Other", Function: noop })
+	return registry
+}
+
+func suiteNames(suites []*TestSuite) string {
+	var names = []string{}
+	for _, suite := range suites { names = append(names, suite.Name) }
+	return strings.Join(names, ",")
+}
+
+/*******************************************************************************
+ * A suite that is named more than once - e.g., -tests=Step2,Step1,Step2 - is
+ * selected once, rather than leaving a copy that can never be scheduled.
+ */
+func TestSelectDuplicateNames(t *testing.T) {
+	var registry = newTestRegistry()
+	var suites, err = registry.Select([]string{"Step2", "Step1", "Step2", "Other", "Other"}, nil, nil)
+	if err != nil { t.Fatal(err) }
+	if names := suiteNames(suites); names != "Step1,Step2,Other" {
+		t.Errorf("Selected %s, expected Step1,Step2,Other", names)
+	}
+}
+
+func TestSortDuplicateSuite(t *testing.T) {
+	var registry = newTestRegistry()
+	var step1 = registry.GetSuite("Step1")
+	var _, err = registry.Sort([]*TestSuite{ step1, registry.GetSuite("Step2"), step1 })
+	if (err == nil) || ! strings.Contains(err.Error(), "Step1 is given more than once") {
+		t.Errorf("Sort of a duplicated suite returned %v", err)
+	}
+}
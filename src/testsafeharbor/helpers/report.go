@@ -0,0 +1,256 @@
+/*******************************************************************************
+ * Write the results of a test run as JUnit XML and as JSON, so that CI servers
+ * (Jenkins, GitLab) can display per-test history.
+ */
+
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"time"
+	"encoding/xml"
+	"encoding/json"
+)
+
+/*******************************************************************************
+ * The outcome of one Try* step (i.e., one call to StartTest).
+ */
+type TestResult struct {
+	Suite string
+	Name string
+	Number int
+	Key string  // the key used in TestContext.TestStatus
+	StartTime time.Time
+	Duration time.Duration
+	Messages []string
+	StackTrace string
+	SchemaViolations []string  // see schema.go
+}
+
+/*******************************************************************************
+ * Return the status of the test: "Pass", "Fail", or "Incomplete" if the test
+ * neither passed nor failed explicitly.
+ */
+func (testContext *TestContext) GetTestResultStatus(result *TestResult) string {
+	var status = testContext.TestStatus[result.Key]
+	if status == "" { return "Incomplete" }
+	return status
+}
+
+/*******************************************************************************
+ * Write the JUnit XML report and the JSON report, to <pathPrefix>.xml and
+ * <pathPrefix>.json respectively.
+ */
+func (testContext *TestContext) WriteReports(pathPrefix string) error {
+	var err = testContext.WriteJUnitReport(pathPrefix + ".xml")
+	if err != nil { return err }
+	return testContext.WriteJSONReport(pathPrefix + ".json")
+}
+
+/*******************************************************************************
+ * Group the test results by suite, preserving the order in which the suites
+ * were run.
+ */
+func (testContext *TestContext) getResultsBySuite() ([]string, map[string][]*TestResult) {
+	var suiteNames = []string{}
+	var resultsBySuite = make(map[string][]*TestResult)
+	for _, result := range testContext.TestResults {
+		var results, exists = resultsBySuite[result.Suite]
+		if ! exists { suiteNames = append(suiteNames, result.Suite) }
+		resultsBySuite[result.Suite] = append(results, result)
+	}
+	return suiteNames, resultsBySuite
+}
+
+/*******************************************************************************
+ * Types for marshalling a JUnit XML report.
+ */
+type junitTestSuites struct {
+	XMLName xml.Name `xml:"testsuites"`
+	Name string `xml:"name,attr"`
+	Tests int `xml:"tests,attr"`
+	Failures int `xml:"failures,attr"`
+	Errors int `xml:"errors,attr"`
+	Time string `xml:"time,attr"`
+	Suites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name string `xml:"name,attr"`
+	Tests int `xml:"tests,attr"`
+	Failures int `xml:"failures,attr"`
+	Errors int `xml:"errors,attr"`
+	Time string `xml:"time,attr"`
+	Timestamp string `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name string `xml:"name,attr"`
+	ClassName string `xml:"classname,attr"`
+	Time string `xml:"time,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+	Error *junitFailure `xml:"error,omitempty"`
+	SystemOut string `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+/*******************************************************************************
+ * Write the test results to the specified file in JUnit XML format. Each suite
+ * becomes a <testsuite>, and each Try* step becomes a <testcase>. A test that
+ * neither passed nor failed - i.e., that returned without reaching
+ * PassTestIfNoFailures - is reported as an <error>: it is not known to pass.
+ */
+func (testContext *TestContext) WriteJUnitReport(path string) error {
+
+	var report = junitTestSuites{ Name: "testsafeharbor" }
+	var totalDuration time.Duration
+
+	var suiteNames, resultsBySuite = testContext.getResultsBySuite()
+	for _, suiteName := range suiteNames {
+		var results = resultsBySuite[suiteName]
+		var suite = junitTestSuite{
+			Name: suiteName,
+			Timestamp: results[0].StartTime.Format("2006-01-02T15:04:05"),
+		}
+		var suiteDuration time.Duration
+		for _, result := range results {
+			var testCase = junitTestCase{
+				Name: result.Name,
+				ClassName: "testsafeharbor." + suiteName,
+				Time: junitSeconds(result.Duration),
+			}
+			switch testContext.GetTestResultStatus(result) {
+			case "Fail":
+				var message = "Test failed"
+				if len(result.Messages) > 0 { message = result.Messages[0] }
+				testCase.Failure = &junitFailure{
+					Message: message,
+					Type: "Fail",
+					Contents: result.StackTrace,
+				}
+				suite.Failures++
+			case "Incomplete":
+				testCase.Error = &junitFailure{
+					Message: "Test did not report a result",
+					Type: "Incomplete",
+				}
+				suite.Errors++
+			}
+			for i, msg := range result.Messages {
+				if i > 0 { testCase.SystemOut = testCase.SystemOut + "\n" }
+				testCase.SystemOut = testCase.SystemOut + msg
+			}
+			for _, violation := range result.SchemaViolations {
+				if testCase.SystemOut != "" { testCase.SystemOut = testCase.SystemOut + "\n" }
+				testCase.SystemOut = testCase.SystemOut + "Schema: " + violation
+			}
+			suite.TestCases = append(suite.TestCases, testCase)
+			suite.Tests++
+			suiteDuration = suiteDuration + result.Duration
+		}
+		suite.Time = junitSeconds(suiteDuration)
+		report.Suites = append(report.Suites, suite)
+		report.Tests = report.Tests + suite.Tests
+		report.Failures = report.Failures + suite.Failures
+		report.Errors = report.Errors + suite.Errors
+		totalDuration = totalDuration + suiteDuration
+	}
+	report.Time = junitSeconds(totalDuration)
+
+	var bytes []byte
+	var err error
+	bytes, err = xml.MarshalIndent(report, "", "\t")
+	if err != nil { return err }
+	bytes = append([]byte(xml.Header), bytes...)
+	bytes = append(bytes, '\n')
+	return writeReportFile(path, bytes)
+}
+
+/*******************************************************************************
+ * Types for marshalling a JSON report.
+ */
+type jsonReport struct {
+	NoOfTests int
+	NoOfTestsThatFailed int
+	Suites []jsonSuite
+}
+
+type jsonSuite struct {
+	Name string
+	Tests []jsonTest
+}
+
+type jsonTest struct {
+	Number int
+	Name string
+	Status string
+	StartTime string
+	DurationSeconds float64
+	Messages []string
+	StackTrace string `json:",omitempty"`
+	SchemaViolations []string `json:",omitempty"`
+}
+
+/*******************************************************************************
+ * Write the test results to the specified file as a JSON document.
+ */
+func (testContext *TestContext) WriteJSONReport(path string) error {
+
+	var report = jsonReport{
+		NoOfTests: testContext.NoOfTests,
+		NoOfTestsThatFailed: testContext.NoOfTestsThatFailed,
+		Suites: []jsonSuite{},
+	}
+
+	var suiteNames, resultsBySuite = testContext.getResultsBySuite()
+	for _, suiteName := range suiteNames {
+		var suite = jsonSuite{ Name: suiteName, Tests: []jsonTest{} }
+		for _, result := range resultsBySuite[suiteName] {
+			suite.Tests = append(suite.Tests, jsonTest{
+				Number: result.Number,
+				Name: result.Name,
+				Status: testContext.GetTestResultStatus(result),
+				StartTime: result.StartTime.Format(time.RFC3339),
+				DurationSeconds: result.Duration.Seconds(),
+				Messages: result.Messages,
+				StackTrace: result.StackTrace,
+				SchemaViolations: result.SchemaViolations,
+			})
+		}
+		report.Suites = append(report.Suites, suite)
+	}
+
+	var bytes []byte
+	var err error
+	bytes, err = json.MarshalIndent(report, "", "\t")
+	if err != nil { return err }
+	bytes = append(bytes, '\n')
+	return writeReportFile(path, bytes)
+}
+
+/*******************************************************************************
+ *
+ */
+func writeReportFile(path string, bytes []byte) error {
+	var file *os.File
+	var err error
+	file, err = os.Create(path)
+	if err != nil { return err }
+	_, err = file.Write(bytes)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
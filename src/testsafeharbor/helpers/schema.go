@@ -0,0 +1,289 @@
+/*******************************************************************************
+ * Validation of responses against a schema per endpoint (see schemas.go). Every
+ * 200 response that is received through TestContext is validated against the
+ * schema for its request, and the fields that are unexpected, missing or of the
+ * wrong type are recorded with the current test, and listed in the reports.
+ *
+ * Schemas are a subset of JSON Schema: type, properties, required, items, $ref
+ * (to a definition in schemas.go, by name), allOf and oneOf. Unlike in JSON
+ * Schema, an object may not have properties that its schema does not list,
+ * unless additionalProperties is true; and the properties and required fields
+ * of the schemas in an allOf are merged, so that a desc may extend BaseDesc.
+ */
+
+package helpers
+
+import (
+	"fmt"
+	"bytes"
+	"net/http"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"encoding/json"
+)
+
+/*******************************************************************************
+ * How responses are validated: "off", not at all; "warn", violations are
+ * recorded but do not fail the test; "strict", violations fail the test.
+ */
+const (
+	SchemaOff = "off"
+	SchemaWarn = "warn"
+	SchemaStrict = "strict"
+)
+
+type Schema struct {
+	Type string `json:"type,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required []string `json:"required,omitempty"`
+	AdditionalProperties bool `json:"additionalProperties,omitempty"`
+	Items *Schema `json:"items,omitempty"`
+	Ref string `json:"$ref,omitempty"`
+	AllOf []*Schema `json:"allOf,omitempty"`
+	OneOf []*Schema `json:"oneOf,omitempty"`
+}
+
+var schemaDefinitions map[string]*Schema
+var responseSchemas = make(map[string]*Schema)
+
+func init() {
+	var err = json.Unmarshal([]byte(schemaDefinitionsJSON), &schemaDefinitions)
+	if err != nil { panic("Invalid schema definitions: " + err.Error()) }
+	registerResponseSchemas()
+}
+
+/*******************************************************************************
+ * Return a schema that refers to the named definition.
+ */
+func ref(name string) *Schema {
+	return &Schema{ Ref: name }
+}
+
+/*******************************************************************************
+ * Return the schema of a response that is a list, each element of which
+ * conforms to itemSchema.
+ */
+func listOf(itemSchema *Schema) *Schema {
+	return &Schema{
+		AllOf: []*Schema{ ref("BaseDesc") },
+		Properties: map[string]*Schema{
+			"payload": &Schema{ Type: "array", Items: itemSchema },
+		},
+		Required: []string{ "payload" },
+	}
+}
+
+func oneOf(schemas ...*Schema) *Schema {
+	return &Schema{ OneOf: schemas }
+}
+
+/*******************************************************************************
+ * Return the schema for the response to the named request, or nil if there is
+ * none.
+ */
+func GetResponseSchema(reqName string) *Schema {
+	return responseSchemas[reqName]
+}
+
+/*******************************************************************************
+ * Return the definition that the schema refers to, if it is a $ref.
+ */
+func (schema *Schema) resolve() *Schema {
+	for schema.Ref != "" {
+		var def = schemaDefinitions[schema.Ref]
+		if def == nil { panic("Undefined schema: " + schema.Ref) }
+		schema = def
+	}
+	return schema
+}
+
+/*******************************************************************************
+ * Return true if the schema places no constraints on the response.
+ */
+func (schema *Schema) acceptsAnything() bool {
+	schema = schema.resolve()
+	return (schema.Type == "") && (len(schema.Properties) == 0) &&
+		(len(schema.AllOf) == 0) && (len(schema.OneOf) == 0)
+}
+
+/*******************************************************************************
+ * Merge the properties and required fields of the schema and of its allOf
+ * schemas, recursively.
+ */
+func (schema *Schema) collectProperties(properties map[string]*Schema,
+	required map[string]bool) bool {
+
+	schema = schema.resolve()
+	var additional = schema.AdditionalProperties
+	for _, sub := range schema.AllOf {
+		if sub.collectProperties(properties, required) { additional = true }
+	}
+	for name, propSchema := range schema.Properties { properties[name] = propSchema }
+	for _, name := range schema.Required { required[name] = true }
+	return additional
+}
+
+/*******************************************************************************
+ * Return a description of each way in which the value, parsed from JSON, does
+ * not conform to the schema. path identifies the value in the descriptions.
+ */
+func (schema *Schema) Validate(value interface{}, path string) []string {
+
+	schema = schema.resolve()
+	if len(schema.OneOf) > 0 { return schema.validateOneOf(value, path) }
+
+	var schemaType = schema.Type
+	if (schemaType == "") && ((len(schema.Properties) > 0) || (len(schema.AllOf) > 0)) {
+		schemaType = "object"
+	}
+	if schemaType == "" { return nil }  // anything
+	var valueType = jsonTypeOf(value)
+	if (valueType != schemaType) && ! ((schemaType == "number") && (valueType == "integer")) {
+		return []string{ fmt.Sprintf("%s is %s, expected %s", describePath(path),
+			valueType, schemaType) }
+	}
+
+	var violations = []string{}
+	switch schemaType {
+	case "array":
+		if schema.Items == nil { break }
+		for i, element := range value.([]interface{}) {
+			violations = append(violations,
+				schema.Items.Validate(element, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "object":
+		var fields = value.(map[string]interface{})
+		var properties = make(map[string]*Schema)
+		var required = make(map[string]bool)
+		var additional = schema.collectProperties(properties, required)
+		for _, name := range sortedKeys(required) {
+			if _, present := fields[name]; ! present {
+				violations = append(violations, "missing field " + joinPath(path, name))
+			}
+		}
+		var names = make([]string, 0, len(fields))
+		for name, _ := range fields { names = append(names, name) }
+		sort.Strings(names)
+		for _, name := range names {
+			var propSchema = properties[name]
+			if propSchema == nil {
+				if ! additional {
+					violations = append(violations, "unexpected field " + joinPath(path, name))
+				}
+				continue
+			}
+			if (fields[name] == nil) && ! required[name] { continue }  // null optional field
+			violations = append(violations, propSchema.Validate(fields[name], joinPath(path, name))...)
+		}
+	}
+	return violations
+}
+
+/*******************************************************************************
+ * The value must conform to at least one of the schemas. If it conforms to
+ * none, return the violations of the schema that it comes closest to.
+ */
+func (schema *Schema) validateOneOf(value interface{}, path string) []string {
+	var best []string = nil
+	for _, alternative := range schema.OneOf {
+		var violations = alternative.Validate(value, path)
+		if len(violations) == 0 { return nil }
+		if (best == nil) || (len(violations) < len(best)) { best = violations }
+	}
+	return best
+}
+
+/*******************************************************************************
+ * Return the JSON type of a value that was parsed by encoding/json.
+ */
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil: return "null"
+	case bool: return "boolean"
+	case string: return "string"
+	case float64:
+		if v == float64(int64(v)) { return "integer" }
+		return "number"
+	case []interface{}: return "array"
+	case map[string]interface{}: return "object"
+	default: return fmt.Sprintf("%T", value)
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" { return name }
+	return path + "." + name
+}
+
+func describePath(path string) string {
+	if path == "" { return "response" }
+	return "field " + path
+}
+
+func sortedKeys(m map[string]bool) []string {
+	var keys = make([]string, 0, len(m))
+	for key, _ := range m { keys = append(keys, key) }
+	sort.Strings(keys)
+	return keys
+}
+
+/*******************************************************************************
+ * Validate a response against the schema for its request, unless validation is
+ * off, and record any violations with the current test. Only 200 responses are
+ * validated. The body of the response is left unread.
+ */
+func (testContext *TestContext) validateResponse(reqName string, resp *http.Response) {
+
+	if (testContext.SchemaMode == SchemaOff) || (testContext.SchemaMode == "") { return }
+	if (resp == nil) || (resp.StatusCode != 200) { return }
+
+	var schema = GetResponseSchema(reqName)
+	if schema == nil {
+		testContext.recordSchemaViolations(reqName, []string{ "no schema for response" })
+		return
+	}
+	if schema.acceptsAnything() { return }  // e.g., the content of a file
+
+	var body, err = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil { return }  // the Try* method will encounter the error too
+
+	var value interface{}
+	err = json.Unmarshal(body, &value)
+	if err != nil {
+		testContext.recordSchemaViolations(reqName, []string{ "response is not JSON: " + err.Error() })
+		return
+	}
+	testContext.recordSchemaViolations(reqName, schema.Validate(value, ""))
+}
+
+/*******************************************************************************
+ * Record the violations with the current test. In strict mode, they also fail
+ * the test.
+ */
+func (testContext *TestContext) recordSchemaViolations(reqName string, violations []string) {
+	if len(violations) == 0 { return }
+	for _, violation := range violations {
+		var msg = "Schema: " + reqName + ": " + violation
+		fmt.Println(msg)
+		if testContext.currentResult != nil {
+			testContext.currentResult.SchemaViolations =
+				append(testContext.currentResult.SchemaViolations, reqName + ": " + violation)
+		}
+	}
+	if testContext.SchemaMode == SchemaStrict {
+		testContext.FailTestWithMessage(fmt.Sprintf("%s: response does not match schema: %s",
+			reqName, strings.Join(violations, "; ")))
+	}
+}
+
+/*******************************************************************************
+ * Return the number of schema violations recorded, in all tests.
+ */
+func (testContext *TestContext) GetNoOfSchemaViolations() int {
+	var n = 0
+	for _, result := range testContext.TestResults { n = n + len(result.SchemaViolations) }
+	return n
+}
@@ -0,0 +1,340 @@
+/*******************************************************************************
+ * The schema of the response to each SafeHarbor request. The definitions are
+ * those of the descs that the server returns; see schema.go for the dialect.
+ * A field is listed in "required" if the server always returns it.
+ */
+
+package helpers
+
+const schemaDefinitionsJSON = `{
+	"BaseDesc": {
+		"properties": {
+			"HTTPStatusCode": { "type": "integer" },
+			"HTTPReasonPhrase": { "type": "string" },
+			"ObjectType": { "type": "string" }
+		},
+		"required": ["HTTPStatusCode", "HTTPReasonPhrase", "ObjectType"]
+	},
+	"Result": {
+		"allOf": [{ "$ref": "BaseDesc" }]
+	},
+	"ParameterValueDesc": {
+		"properties": {
+			"Name": { "type": "string" },
+			"Value": { "type": "string" }
+		},
+		"required": ["Name", "Value"]
+	},
+	"StringList": {
+		"type": "array",
+		"items": { "type": "string" }
+	},
+	"ByteList": {
+		"type": "array",
+		"items": { "type": "integer" }
+	},
+	"ParameterValueList": {
+		"type": "array",
+		"items": { "$ref": "ParameterValueDesc" }
+	},
+	"SessionToken": {
+		"allOf": [{ "$ref": "BaseDesc" }],
+		"properties": {
+			"UniqueSessionId": { "type": "string" },
+			"AuthenticatedUserid": { "type": "string" },
+			"RealmId": { "type": "string" },
+			"IsAdmin": { "type": "boolean" }
+		},
+		"required": ["UniqueSessionId", "AuthenticatedUserid", "IsAdmin"]
+	},
+	"RealmDesc": {
+		"allOf": [{ "$ref": "BaseDesc" }],
+		"properties": {
+			"Id": { "type": "string" },
+			"Name": { "type": "string" },
+			"OrgFullName": { "type": "string" },
+			"AdminUserId": { "type": "string" },
+			"Description": { "type": "string" },
+			"CreationDate": { "type": "string" }
+		},
+		"required": ["Id", "Name", "OrgFullName", "AdminUserId"]
+	},
+	"UserDesc": {
+		"allOf": [{ "$ref": "BaseDesc" }],
+		"properties": {
+			"Id": { "type": "string" },
+			"UserId": { "type": "string" },
+			"Name": { "type": "string" },
+			"EmailAddress": { "type": "string" },
+			"RealmId": { "type": "string" },
+			"Enabled": { "type": "boolean" },
+			"DefaultRepoId": { "type": "string" },
+			"CanModifyTheseRealms": { "$ref": "StringList" }
+		},
+		"required": ["Id", "UserId", "Name", "RealmId", "CanModifyTheseRealms"]
+	},
+	"GroupDesc": {
+		"allOf": [{ "$ref": "BaseDesc" }],
+		"properties": {
+			"Id": { "type": "string" },
+			"RealmId": { "type": "string" },
+			"Name": { "type": "string" },
+			"Description": { "type": "string" },
+			"CreationDate": { "type": "string" }
+		},
+		"required": ["Id", "RealmId", "Name", "Description", "CreationDate"]
+	},
+	"RepoDesc": {
+		"allOf": [{ "$ref": "BaseDesc" }],
+		"properties": {
+			"Id": { "type": "string" },
+			"RealmId": { "type": "string" },
+			"Name": { "type": "string" },
+			"Description": { "type": "string" },
+			"CreationDate": { "type": "string" },
+			"DockerfileIds": { "$ref": "StringList" }
+		},
+		"required": ["Id", "RealmId", "Name", "Description", "CreationDate", "DockerfileIds"]
+	},
+	"DockerfileDesc": {
+		"allOf": [{ "$ref": "BaseDesc" }],
+		"properties": {
+			"Id": { "type": "string" },
+			"RepoId": { "type": "string" },
+			"Name": { "type": "string" },
+			"Description": { "type": "string" },
+			"CreationDate": { "type": "string" },
+			"ParameterValueDescs": { "$ref": "ParameterValueList" }
+		},
+		"required": ["Id", "RepoId", "Name"]
+	},
+	"DockerImageDesc": {
+		"allOf": [{ "$ref": "BaseDesc" }],
+		"properties": {
+			"ObjId": { "type": "string" },
+			"RepoId": { "type": "string" },
+			"Name": { "type": "string" },
+			"Description": { "type": "string" },
+			"CreationDate": { "type": "string" },
+			"ScanConfigIds": { "$ref": "StringList" }
+		},
+		"required": ["ObjId", "Name"]
+	},
+	"DockerImageVersionDesc": {
+		"allOf": [{ "$ref": "BaseDesc" }],
+		"properties": {
+			"ObjId": { "type": "string" },
+			"Version": { "type": "string" },
+			"ImageObjId": { "type": "string" },
+			"ImageName": { "type": "string" },
+			"RepoId": { "type": "string" },
+			"ImageCreationEventId": { "type": "string" },
+			"CreationDate": { "type": "string" },
+			"Digest": { "$ref": "ByteList" },
+			"Signature": { "$ref": "ByteList" },
+			"ScanEventIds": { "$ref": "StringList" },
+			"DockerBuildOutput": { "type": "string" }
+		},
+		"required": ["ObjId", "Version", "ImageObjId", "ImageCreationEventId", "CreationDate"]
+	},
+	"ScanConfigDesc": {
+		"allOf": [{ "$ref": "BaseDesc" }],
+		"properties": {
+			"Id": { "type": "string" },
+			"RepoId": { "type": "string" },
+			"Name": { "type": "string" },
+			"Description": { "type": "string" },
+			"ProviderName": { "type": "string" },
+			"SuccessExpression": { "type": "string" },
+			"FlagId": { "type": "string" },
+			"ScanParameterValueDescs": { "$ref": "ParameterValueList" },
+			"DockerImagesIdsThatUse": { "$ref": "StringList" }
+		},
+		"required": ["Id", "ProviderName", "ScanParameterValueDescs"]
+	},
+	"FlagDesc": {
+		"allOf": [{ "$ref": "BaseDesc" }],
+		"properties": {
+			"FlagId": { "type": "string" },
+			"RepoId": { "type": "string" },
+			"Name": { "type": "string" },
+			"Description": { "type": "string" },
+			"ImageURL": { "type": "string" }
+		},
+		"required": ["FlagId", "RepoId", "Name", "ImageURL"]
+	},
+	"PermissionDesc": {
+		"allOf": [{ "$ref": "BaseDesc" }],
+		"properties": {
+			"ACLEntryId": { "type": "string" },
+			"PartyId": { "type": "string" },
+			"ResourceId": { "type": "string" },
+			"CanCreateIn": { "type": "boolean" },
+			"CanRead": { "type": "boolean" },
+			"CanWrite": { "type": "boolean" },
+			"CanExecute": { "type": "boolean" },
+			"CanDelete": { "type": "boolean" }
+		},
+		"required": ["PartyId", "ResourceId", "CanCreateIn", "CanRead", "CanWrite",
+			"CanExecute", "CanDelete"]
+	},
+	"DockerfileExecEventDesc": {
+		"allOf": [{ "$ref": "BaseDesc" }],
+		"properties": {
+			"Id": { "type": "string" },
+			"When": { "type": "string" },
+			"UserObjId": { "type": "string" },
+			"DockerfileId": { "type": "string" },
+			"ImageVersionObjId": { "type": "string" },
+			"ParameterValues": { "$ref": "ParameterValueList" },
+			"DockerfileContent": { "type": "string" }
+		},
+		"required": ["Id", "When", "UserObjId", "DockerfileId", "ParameterValues",
+			"DockerfileContent"]
+	},
+	"VulnerabilityDesc": {
+		"properties": {
+			"VCE_ID": { "type": "string" },
+			"Description": { "type": "string" }
+		},
+		"required": ["VCE_ID"]
+	},
+	"ScanEventDesc": {
+		"allOf": [{ "$ref": "BaseDesc" }],
+		"properties": {
+			"Id": { "type": "string" },
+			"When": { "type": "string" },
+			"UserObjId": { "type": "string" },
+			"ScanConfigId": { "type": "string" },
+			"ProviderName": { "type": "string" },
+			"ParameterValueDescs": { "$ref": "ParameterValueList" },
+			"ImageVersionObjId": { "type": "string" },
+			"Score": { "type": "string" },
+			"VulnerabilityDescs": {
+				"type": "array",
+				"items": { "$ref": "VulnerabilityDesc" }
+			}
+		},
+		"required": ["Id", "When", "UserObjId", "ScanConfigId", "Score", "VulnerabilityDescs"]
+	},
+	"ImageStatusDesc": {
+		"allOf": [{ "$ref": "BaseDesc" }],
+		"properties": {
+			"EventId": { "type": "string" },
+			"When": { "type": "string" },
+			"UserObjId": { "type": "string" },
+			"ScanConfigId": { "type": "string" },
+			"ProviderName": { "type": "string" },
+			"ParameterValueDescs": { "$ref": "ParameterValueList" },
+			"Score": { "type": "string" }
+		}
+	},
+	"ScanProviderDesc": {
+		"allOf": [{ "$ref": "BaseDesc" }],
+		"properties": {
+			"Name": { "type": "string" },
+			"Description": { "type": "string" },
+			"Parameters": {
+				"type": "array",
+				"items": {
+					"properties": {
+						"Name": { "type": "string" },
+						"Description": { "type": "string" }
+					},
+					"required": ["Name"]
+				}
+			}
+		},
+		"required": ["Name", "Parameters"]
+	},
+	"File": {}
+}`
+
+/*******************************************************************************
+ * Associate each request with the schema of its response.
+ */
+func registerResponseSchemas() {
+
+	var eventDesc = oneOf(ref("DockerfileExecEventDesc"), ref("ScanEventDesc"))
+
+	var schemas = map[string]*Schema{
+		"ping": ref("Result"),
+		"clearAll": ref("Result"),
+		"createRealmAnon": ref("UserDesc"),
+		"authenticate": ref("SessionToken"),
+		"logout": ref("Result"),
+		"createRealm": ref("RealmDesc"),
+		"getRealmDesc": ref("RealmDesc"),
+		"getRealmByName": ref("RealmDesc"),
+		"getAllRealms": listOf(ref("RealmDesc")),
+		"getRealmUsers": listOf(ref("UserDesc")),
+		"getRealmGroups": listOf(ref("GroupDesc")),
+		"getRealmRepos": listOf(ref("RepoDesc")),
+		"remRealmUser": ref("Result"),
+		"deactivateRealm": ref("Result"),
+		"createUser": ref("UserDesc"),
+		"getUserDesc": ref("UserDesc"),
+		"getMyDesc": ref("UserDesc"),
+		"disableUser": ref("Result"),
+		"reenableUser": ref("Result"),
+		"updateUserInfo": ref("UserDesc"),
+		"userExists": ref("Result"),
+		"changePassword": ref("Result"),
+		"moveUserToRealm": ref("Result"),
+		"createGroup": ref("GroupDesc"),
+		"getGroupDesc": ref("GroupDesc"),
+		"getGroupUsers": listOf(ref("UserDesc")),
+		"addGroupUser": ref("Result"),
+		"remGroupUser": ref("Result"),
+		"deleteGroup": ref("Result"),
+		"getMyGroups": listOf(ref("GroupDesc")),
+		"getMyRealms": listOf(ref("RealmDesc")),
+		"getMyRepos": listOf(ref("RepoDesc")),
+		"getMyDockerfiles": listOf(ref("DockerfileDesc")),
+		"getMyDockerImages": listOf(ref("DockerImageDesc")),
+		"getMyScanConfigs": listOf(ref("ScanConfigDesc")),
+		"getMyFlags": listOf(ref("FlagDesc")),
+		"createRepo": ref("RepoDesc"),
+		"getRepoDesc": ref("RepoDesc"),
+		"deleteRepo": ref("Result"),
+		"addDockerfile": ref("DockerfileDesc"),
+		"getDockerfiles": listOf(ref("DockerfileDesc")),
+		"getDockerfileDesc": ref("DockerfileDesc"),
+		"replaceDockerfile": ref("Result"),
+		"remDockerfile": ref("Result"),
+		"execDockerfile": ref("DockerImageVersionDesc"),
+		"addAndExecDockerfile": ref("DockerImageVersionDesc"),
+		"getDockerImages": listOf(ref("DockerImageDesc")),
+		"getDockerImageDesc": oneOf(ref("DockerImageDesc"), ref("DockerImageVersionDesc")),
+		"getDockerImageVersions": listOf(ref("DockerImageVersionDesc")),
+		"remDockerImage": ref("Result"),
+		"remImageVersion": ref("Result"),
+		"downloadImage": ref("File"),
+		"getDockerImageStatus": ref("ImageStatusDesc"),
+		"getEventDesc": eventDesc,
+		"getUserEvents": listOf(eventDesc),
+		"getDockerImageEvents": listOf(eventDesc),
+		"getDockerfileEvents": listOf(ref("DockerfileExecEventDesc")),
+		"setPermission": ref("PermissionDesc"),
+		"addPermission": ref("PermissionDesc"),
+		"getPermission": ref("PermissionDesc"),
+		"remPermission": ref("Result"),
+		"getScanProviders": listOf(ref("ScanProviderDesc")),
+		"defineScanConfig": ref("ScanConfigDesc"),
+		"updateScanConfig": ref("ScanConfigDesc"),
+		"getScanConfigDesc": ref("ScanConfigDesc"),
+		"getScanConfigDescByName": ref("ScanConfigDesc"),
+		"remScanConfig": ref("Result"),
+		"useScanConfigForImage": ref("Result"),
+		"stopUsingScanConfigForImage": ref("Result"),
+		"scanImage": listOf(ref("ScanEventDesc")),
+		"defineFlag": ref("FlagDesc"),
+		"getFlagDesc": ref("FlagDesc"),
+		"getFlagDescByName": ref("FlagDesc"),
+		"getFlagImage": ref("File"),
+		"remFlag": ref("Result"),
+		"enableEmailVerification": ref("Result"),
+		"validateAccountVerificationToken": ref("Result"),
+	}
+	for reqName, schema := range schemas { responseSchemas[reqName] = schema }
+}
@@ -12,7 +12,7 @@ import (
 	//"io"
 	"io/ioutil"
 	"os"
-	//"path/filepath"
+	"path/filepath"
 	//"mime/multipart"
 	//"bufio"
 	//"bytes"
@@ -23,10 +23,13 @@ import (
 	"crypto/sha256"
 	"crypto/sha512"
 	"runtime/debug"	
+	"time"
+	"sync"
 	
 	// My packages:
 	"rest"
 	"utilities"
+	"testsafeharbor/client"
 )
 
 type TestContext struct {
@@ -36,11 +39,25 @@ type TestContext struct {
 	testName string
 	StopOnFirstError bool
 	TestStatus map[string]string
+	TestResults []*TestResult
+	suiteName string
+	currentResult *TestResult
 	CurrentTestPassed bool
 	NoOfTests int
 	NoOfTestsThatFailed int
 	RedisPswd string
 	NoLargeFileTransfers bool
+	Parallel bool  // true if other suites are running concurrently
+	NameSuffix string  // appended to names by Unique
+	ClearAllRequested bool  // set if TryClearAll was deferred because of Parallel
+	setSessionId func(req *http.Request, sessionId string)
+	cassetteRecorder *CassetteRecorder  // non-nil in record mode
+	cassettePlayer *CassettePlayer  // non-nil in replay mode
+	SchemaMode string  // SchemaOff, SchemaWarn or SchemaStrict; see schema.go
+	Config *Config  // settings for the suites; see config.go
+	TestTimeout time.Duration  // the deadline of each test, after it starts; see containment.go
+	testTimeout time.Duration  // that of the current test
+	testDeadline time.Time  // zero if the current test has none
 }
 
 func NewTestContext(scheme, hostname string, port int,
@@ -52,13 +69,103 @@ func NewTestContext(scheme, hostname string, port int,
 		SessionId: "",
 		StopOnFirstError: stopOnFirstError,
 		TestStatus:  make(map[string]string),
+		TestResults: make([]*TestResult, 0),
 		NoOfTests:  0,
 		NoOfTestsThatFailed: 0,
 		RedisPswd: redisPswd,
 		NoLargeFileTransfers: nolargefiles,
+		setSessionId: setSessionId,
 	}
 }
 
+/*******************************************************************************
+ * Record each request sent to the server, and the response, as a cassette in
+ * the specified directory. See cassette.go.
+ */
+func (testContext *TestContext) RecordCassettesTo(dir string) {
+	testContext.cassetteRecorder = NewCassetteRecorder(dir)
+}
+
+/*******************************************************************************
+ * Instead of sending requests to the server, send them to a local stand-in
+ * server that replays the cassettes in the specified directory.
+ */
+func (testContext *TestContext) ReplayCassettesFrom(dir string) error {
+	var player *CassettePlayer
+	var err error
+	player, err = NewCassettePlayer(dir)
+	if err != nil { return err }
+	testContext.cassettePlayer = player
+	testContext.RestContext = *rest.CreateTCPRestContext("http", "127.0.0.1",
+		player.GetPort(), "", "", nil, testContext.setSessionId)
+	return nil
+}
+
+/*******************************************************************************
+ * The methods below shadow those of the embedded RestContext, so that requests
+ * can be recorded, responses validated against their schema, and requests
+ * abandoned at the test's deadline.
+ */
+func (testContext *TestContext) SendSessionGet(sessionId string, reqName string,
+	names []string, values []string) (*http.Response, error) {
+	
+	var resp, err = testContext.sendBeforeDeadline(reqName, func() (*http.Response, error) {
+		return testContext.RestContext.SendSessionGet(sessionId, reqName, names, values)
+	})
+	testContext.recordCassette("GET", sessionId, reqName, names, values, "", resp, err)
+	testContext.validateResponse(reqName, resp)
+	return resp, err
+}
+
+func (testContext *TestContext) SendSessionPost(sessionId string, reqName string,
+	names []string, values []string) (*http.Response, error) {
+	
+	var resp, err = testContext.sendBeforeDeadline(reqName, func() (*http.Response, error) {
+		return testContext.RestContext.SendSessionPost(sessionId, reqName, names, values)
+	})
+	testContext.recordCassette("POST", sessionId, reqName, names, values, "", resp, err)
+	testContext.validateResponse(reqName, resp)
+	return resp, err
+}
+
+func (testContext *TestContext) SendSessionFilePost(sessionId string, reqName string,
+	names []string, values []string, path string) (*http.Response, error) {
+	
+	var resp, err = testContext.sendBeforeDeadline(reqName, func() (*http.Response, error) {
+		return testContext.RestContext.SendSessionFilePost(sessionId, reqName,
+			names, values, path)
+	})
+	testContext.recordCassette("FILEPOST", sessionId, reqName, names, values, path, resp, err)
+	testContext.validateResponse(reqName, resp)
+	return resp, err
+}
+
+/*******************************************************************************
+ * If in record mode, write a cassette for the request and response.
+ */
+func (testContext *TestContext) recordCassette(method, sessionId, reqName string,
+	names, values []string, path string, resp *http.Response, sendErr error) {
+	
+	if testContext.cassetteRecorder == nil { return }
+	var cassette = &Cassette{
+		Method: method,
+		ReqName: reqName,
+		WithSession: (sessionId != ""),
+		Names: names,
+		Values: values,
+	}
+	if path != "" { cassette.FileName = filepath.Base(path) }
+	var err error
+	if sendErr != nil {
+		cassette.Error = sendErr.Error()
+	} else {
+		cassette.Response, err = newCassetteResponse(resp)
+		if err != nil { cassette.Error = err.Error() }
+	}
+	err = testContext.cassetteRecorder.Record(cassette)
+	if err != nil { testContext.AbortAllTests("Unable to record cassette: " + err.Error()) }
+}
+
 func (testContext *TestContext) Print() {
 	testContext.RestContext.Print()
 	fmt.Println("TestContext:")
@@ -69,7 +176,18 @@ func (testContext *TestContext) Print() {
 	fmt.Println(fmt.Sprintf("\tCurrentTestPassed: %v", testContext.CurrentTestPassed))
 	fmt.Println(fmt.Sprintf("\tNoOfTests: %d", testContext.NoOfTests))
 	fmt.Println(fmt.Sprintf("\tNoOfTestsThatFailed: %d", testContext.NoOfTestsThatFailed))
-	fmt.Println(fmt.Sprintf("\tRedisPswd: %s", testContext.RedisPswd))
+	if testContext.RedisPswd != "" { fmt.Println("\tRedisPswd: ********") }
+	fmt.Println(fmt.Sprintf("\tParallel: %v", testContext.Parallel))
+	fmt.Println(fmt.Sprintf("\tNameSuffix: %s", testContext.NameSuffix))
+}
+
+/*******************************************************************************
+ * Return the specified realm, user or repo name, made unique to this context,
+ * so that suites that run in parallel do not collide. When suites are run one
+ * at a time, the name is returned unchanged.
+ */
+func (testContext *TestContext) Unique(name string) string {
+	return name + testContext.NameSuffix
 }
 
 
@@ -85,6 +203,22 @@ func (testContext *TestContext) GetTestsThatFailed() []string {
 	return testsThatFailed
 }
 
+/*******************************************************************************
+ * Append the results of the other context to the results of this context. The
+ * other context's tests are renumbered so that they follow this context's tests.
+ */
+func (testContext *TestContext) MergeResults(other *TestContext) {
+	for _, result := range other.TestResults {
+		testContext.NoOfTests++
+		var merged TestResult = *result
+		merged.Number = testContext.NoOfTests
+		merged.Key = fmt.Sprintf("%d: %s", merged.Number, merged.Name)
+		testContext.TestStatus[merged.Key] = other.TestStatus[result.Key]
+		testContext.TestResults = append(testContext.TestResults, &merged)
+	}
+	testContext.NoOfTestsThatFailed = testContext.NoOfTestsThatFailed + other.NoOfTestsThatFailed
+}
+
 /*******************************************************************************
  * 
  */
@@ -92,6 +226,68 @@ func (testContext *TestContext) GetCurrentTestName() string {
 	return testContext.testName
 }
 
+/*******************************************************************************
+ * Mark the start of a test suite. Tests that are started after this call are
+ * recorded as belonging to the suite, for the purpose of reporting.
+ */
+func (testContext *TestContext) StartSuite(name string) {
+	testContext.endCurrentTest()
+	testContext.suiteName = name
+	if testContext.cassetteRecorder != nil {
+		var err = testContext.cassetteRecorder.StartSuite(name)
+		if err != nil { testContext.AbortAllTests("Unable to record cassettes: " + err.Error()) }
+	}
+	if testContext.cassettePlayer != nil {
+		var err = testContext.cassettePlayer.StartSuite(name)
+		if err != nil { testContext.AbortAllTests("Unable to replay cassettes: " + err.Error()) }
+	}
+}
+
+/*******************************************************************************
+ * Mark the end of the test run, so that the duration of the last test is
+ * recorded, and stop the cassette player, if replaying. Call this before
+ * writing any reports.
+ */
+func (testContext *TestContext) FinishTests() {
+	testContext.endCurrentTest()
+	if testContext.cassettePlayer != nil {
+		testContext.cassettePlayer.Close()
+		testContext.cassettePlayer = nil
+	}
+}
+
+/*******************************************************************************
+ * A test is deemed to have ended when the next test (or suite) starts.
+ */
+func (testContext *TestContext) endCurrentTest() {
+	var result = testContext.currentResult
+	if result == nil { return }
+	result.Duration = time.Since(result.StartTime)
+	testContext.checkDeadline(result)
+	testContext.currentResult = nil
+	testContext.testDeadline = time.Time{}
+}
+
+/*******************************************************************************
+ * Add a message to the record for the current test, if there is one.
+ */
+func (testContext *TestContext) recordMessage(msg string) {
+	if testContext.currentResult == nil { return }
+	if msg == "" { return }
+	testContext.currentResult.Messages = append(testContext.currentResult.Messages, msg)
+}
+
+/*******************************************************************************
+ * Record the stack trace for the current test, and write it to stderr.
+ */
+func (testContext *TestContext) recordStackTrace() {
+	var stack []byte = debug.Stack()
+	os.Stderr.Write(stack)
+	if testContext.currentResult == nil { return }
+	if testContext.currentResult.StackTrace != "" { return }
+	testContext.currentResult.StackTrace = string(stack)
+}
+
 /*******************************************************************************
  * Write this line to the server''s stdout at the start of each test.
  */
@@ -105,15 +301,32 @@ func (testContext *TestContext) TestDemarcation() string {
 func (testContext *TestContext) StartTest(name string) {
 	
 	if testContext.StopOnFirstError && (testContext.NoOfTestsThatFailed > 0) {
-		testContext.AbortAllTests(fmt.Sprintf("After test number %d, before test %s",
-			testContext.NoOfTests, name))
+		panic(&abortSignal{
+			msg: fmt.Sprintf("After test number %d, before test %s", testContext.NoOfTests, name),
+			stopping: true,
+		})
 	}
+	testContext.startTest(name)
+}
+
+func (testContext *TestContext) startTest(name string) {
 	testContext.NoOfTests++
 	var testNumber = testContext.NoOfTests
 	var hashKey = fmt.Sprintf("%d: %s", testNumber, name)
 	testContext.testName = hashKey
 	testContext.CurrentTestPassed = false
 	testContext.TestStatus[hashKey] = ""
+	testContext.endCurrentTest()
+	testContext.currentResult = &TestResult{
+		Suite: testContext.suiteName,
+		Name: name,
+		Number: testNumber,
+		Key: hashKey,
+		StartTime: time.Now(),
+		Messages: []string{},
+	}
+	testContext.TestResults = append(testContext.TestResults, testContext.currentResult)
+	testContext.SetTestTimeout(testContext.TestTimeout)
 	fmt.Println()
 	fmt.Println(testNumber, "Begin Test", name, "-------------------------------------------")
 }
@@ -133,25 +346,37 @@ func (testContext *TestContext) PassTestIfNoFailures() bool {
  * 
  */
 func (testContext *TestContext) FailTest() {
+	testContext.startSetupTestIfNoTest()
 	if testContext.TestStatus[testContext.testName] == "Fail" { return }
 	testContext.NoOfTestsThatFailed++
 	testContext.TestStatus[testContext.testName] = "Fail"
 	fmt.Println("Failed test", testContext.testName)
 	fmt.Println("Stack trace:")
-	debug.PrintStack()
+	testContext.recordStackTrace()
 }
 
 /*******************************************************************************
  * 
  */
 func (testContext *TestContext) FailTestWithMessage(message string) {
+	testContext.startSetupTestIfNoTest()
 	if testContext.TestStatus[testContext.testName] == "Fail" { return }
 	testContext.NoOfTestsThatFailed++
 	testContext.TestStatus[testContext.testName] = "Fail"
 	fmt.Println("Failed test", testContext.testName)
 	fmt.Println(message)
+	testContext.recordMessage(message)
 	fmt.Println("Stack trace:")
-	debug.PrintStack()
+	testContext.recordStackTrace()
+}
+
+/*******************************************************************************
+ * A failure outside of any test - e.g., an assertion in the setup of a suite,
+ * before its first test starts - is recorded as the failure of a "setup" test
+ * of the suite, so that the reports account for every failure.
+ */
+func (testContext *TestContext) startSetupTestIfNoTest() {
+	if testContext.currentResult == nil { testContext.startTest("setup") }
 }
 
 /*******************************************************************************
@@ -162,11 +387,11 @@ func (testContext *TestContext) TestHasFailed() bool {
 }
 
 /*******************************************************************************
- * 
+ * Abandon the current suite: the runner records a failure, and goes on to the
+ * next suite. See RunProtected.
  */
 func (testContext *TestContext) AbortAllTests(msg string) {
-	fmt.Println("Aborting tests: " + msg)
-	os.Exit(1)
+	panic(&abortSignal{ msg: msg })
 }
 
 /*******************************************************************************
@@ -176,6 +401,7 @@ func (testContext *TestContext) AssertThat(condition bool, msg string) bool {
 	if ! condition {
 		testContext.FailTest()
 		fmt.Println(fmt.Sprintf("ERROR: %s", msg))
+		testContext.recordMessage(msg)
 	}
 	return condition
 }
@@ -187,6 +413,7 @@ func (testContext *TestContext) AssertOKResponse(resp *http.Response) {
 	if ! testContext.Verify200Response(resp) {
 		testContext.FailTest()
 		fmt.Println("Response status: " + resp.Status)
+		testContext.recordMessage("Response status: " + resp.Status)
 	}
 }
 
@@ -198,9 +425,40 @@ func (testContext *TestContext) AssertErrIsNil(err error, msg string) bool {
 	fmt.Println("Original error message:", err.Error())
 	fmt.Println("Supplemental message:", msg)
 	testContext.FailTest()
+	if msg == "" {
+		testContext.recordMessage(err.Error())
+	} else {
+		testContext.recordMessage(msg + ": " + err.Error())
+	}
 	return false
 }
 
+/*******************************************************************************
+ * For a request that is expected to be refused: fail the test if the request
+ * succeeded, or if it failed for a reason other than the server refusing it.
+ * Return true if the server refused the request.
+ */
+func (testContext *TestContext) AssertServerRefused(err error) bool {
+	if err == nil {
+		testContext.FailTestWithMessage("Request succeeded, but was expected to be refused")
+		return false
+	}
+	if ! client.IsServerError(err) {
+		return testContext.AssertErrIsNil(err, "")
+	}
+	fmt.Println("Refused as expected: " + err.Error())
+	return true
+}
+
+/*******************************************************************************
+ * Return a client for sending requests in the current session. Each request
+ * that it sends includes the current test's demarcation line, so the client
+ * should be obtained after StartTest.
+ */
+func (testContext *TestContext) Client() *client.Client {
+	return client.NewClient(testContext, testContext.SessionId, testContext.TestDemarcation())
+}
+
 /*******************************************************************************
  * 
  */
@@ -261,6 +519,8 @@ func CreateTempFile(dir, name string, content string) (string, error) {
 	return file.Name(), err
 }
 
+var downloadLock sync.Mutex
+
 /*******************************************************************************
  * Retrieve a file at the specified URL and save it to the specified path.
  * If the file already exists at that path, and useCachedFile is true, then
@@ -268,13 +528,18 @@ func CreateTempFile(dir, name string, content string) (string, error) {
  */
 func DownloadFile(url string, finalPath string, useCachedFile bool) error {
 	
+	// Suites that run in parallel download the same files.
+	downloadLock.Lock()
+	defer downloadLock.Unlock()
+	
 	var err error
 	
 	if useCachedFile {
 		var file *os.File
 		file, err = os.Open(finalPath)
-		if err != nil {
+		if err == nil {
 			_, err = file.Stat()
+			file.Close()
 			if err == nil { return nil }  // file exists
 		}
 	}